@@ -0,0 +1,101 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type encodingKey struct{}
+
+// EncodingFromContext returns the content coding chosen by AcceptEncoding, e.g. "gzip" or
+// "identity".
+func EncodingFromContext(ctx context.Context) (string, bool) {
+	enc, ok := ctx.Value(encodingKey{}).(string)
+	return enc, ok
+}
+
+// NegotiateEncoding picks the content coding for a response from an Accept-Encoding header
+// and the codings the server supports, in order of server preference. The coding with the
+// highest quality value wins; "*" matches codings not listed explicitly. Identity is chosen
+// when nothing else is acceptable, unless it is forbidden with "identity;q=0" or "*;q=0", in
+// which case ok is false. A missing header results in identity.
+func NegotiateEncoding(header string, supported ...string) (encoding string, ok bool) {
+	if strings.TrimSpace(header) == "" {
+		return "identity", true
+	}
+
+	qs := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		coding := strings.ToLower(strings.TrimSpace(fields[0]))
+		if coding == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if len(param) > 2 && (param[0] == 'q' || param[0] == 'Q') && param[1] == '=' {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil && v >= 0 && v <= 1 {
+					q = v
+				} else {
+					q = 0
+				}
+			}
+		}
+		qs[coding] = q
+	}
+
+	quality := func(coding string) (float64, bool) {
+		if q, ok := qs[coding]; ok {
+			return q, true
+		}
+		q, ok := qs["*"]
+		return q, ok
+	}
+
+	best, bestQ := "", 0.0
+	for _, coding := range supported {
+		if q, _ := quality(strings.ToLower(coding)); q > bestQ {
+			best, bestQ = coding, q
+		}
+	}
+
+	identityQ, listed := quality("identity")
+	if !listed {
+		// Identity is acceptable unless forbidden, but only as a last resort.
+		identityQ = 0.001
+	}
+	if best != "" && bestQ >= identityQ {
+		return best, true
+	}
+	if identityQ > 0 {
+		return "identity", true
+	}
+	return "", false
+}
+
+// AcceptEncoding is a middleware handler that parses the Accept-Encoding header once and
+// stores the chosen content coding in the context (see EncodingFromContext) for compression
+// middleware to use. Requests that accept none of the supported codings, not even identity,
+// are rejected with 406 Not Acceptable.
+type AcceptEncoding struct {
+	// Supported lists the codings the server can produce, most preferred first.
+	Supported []string
+}
+
+// NewAcceptEncoding returns a new instance of AcceptEncoding supporting gzip and deflate
+func NewAcceptEncoding() *AcceptEncoding {
+	return &AcceptEncoding{Supported: []string{"gzip", "deflate"}}
+}
+
+func (ae *AcceptEncoding) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	enc, ok := NegotiateEncoding(r.Header.Get("Accept-Encoding"), ae.Supported...)
+	if !ok {
+		http.Error(rw, http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+		return
+	}
+
+	next(rw, r.WithContext(context.WithValue(r.Context(), encodingKey{}, enc)))
+}
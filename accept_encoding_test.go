@@ -0,0 +1,63 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	cases := []struct {
+		header string
+		want   string
+		ok     bool
+	}{
+		{"", "identity", true},
+		{"gzip", "gzip", true},
+		{"deflate, gzip", "gzip", true},
+		{"gzip;q=0.5, deflate", "deflate", true},
+		{"gzip;q=0", "identity", true},
+		{"gzip;q=0, deflate;q=0", "identity", true},
+		{"*", "gzip", true},
+		{"*;q=0.5, gzip;q=0", "deflate", true},
+		{"br", "identity", true},
+		{"gzip;q=0.5, identity", "identity", true},
+		{"identity;q=0", "", false},
+		{"br, identity;q=0", "", false},
+		{"gzip, identity;q=0", "gzip", true},
+		{"*;q=0", "", false},
+		{"GZIP;Q=1", "gzip", true},
+		{"gzip;q=bogus", "identity", true},
+	}
+
+	for _, c := range cases {
+		got, ok := NegotiateEncoding(c.header, "gzip", "deflate")
+		if got != c.want || ok != c.ok {
+			t.Errorf("Accept-Encoding %q: expected (%q, %v), got (%q, %v)", c.header, c.want, c.ok, got, ok)
+		}
+	}
+}
+
+func TestAcceptEncoding(t *testing.T) {
+	var enc string
+
+	n := New()
+	n.Use(NewAcceptEncoding())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		enc, _ = EncodingFromContext(r.Context())
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("Accept-Encoding", "deflate;q=0.5, gzip")
+
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, enc, "gzip")
+
+	recorder := httptest.NewRecorder()
+	req.Header.Set("Accept-Encoding", "br, identity;q=0")
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusNotAcceptable)
+}
@@ -0,0 +1,48 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+type representationsKey struct{}
+
+// WithRepresentations returns a copy of ctx carrying the media types the matched route can
+// produce. Routers call it so Acceptable can check the request's Accept header.
+func WithRepresentations(ctx context.Context, types ...string) context.Context {
+	return context.WithValue(ctx, representationsKey{}, types)
+}
+
+// RepresentationsFromContext returns the media types stored with WithRepresentations, if any.
+func RepresentationsFromContext(ctx context.Context) ([]string, bool) {
+	types, ok := ctx.Value(representationsKey{}).([]string)
+	return types, ok
+}
+
+// Acceptable is a middleware handler that answers 406 Not Acceptable, without invoking the
+// rest of the chain, when none of the representations a route can produce satisfies the
+// request's Accept header. The representations come from the context, see
+// WithRepresentations; requests without them, or without an Accept header, are passed
+// through. An Accept header containing */* is always satisfiable.
+type Acceptable struct{}
+
+// NewAcceptable returns a new instance of Acceptable
+func NewAcceptable() *Acceptable {
+	return &Acceptable{}
+}
+
+func (a *Acceptable) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	types, ok := RepresentationsFromContext(r.Context())
+	if !ok || len(types) == 0 || r.Header.Get("Accept") == "" {
+		next(rw, r)
+		return
+	}
+
+	if NegotiateContentType(r, types...) == "" {
+		http.Error(rw, "not acceptable, available: "+strings.Join(types, ", "), http.StatusNotAcceptable)
+		return
+	}
+
+	next(rw, r)
+}
@@ -0,0 +1,55 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAcceptable(t *testing.T) {
+	n := New()
+	n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		next(rw, r.WithContext(WithRepresentations(r.Context(), "application/json", "text/csv")))
+	})
+	n.Use(NewAcceptable())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte("report"))
+	})
+
+	for accept, status := range map[string]int{
+		"":                                     http.StatusOK,
+		"application/json":                     http.StatusOK,
+		"text/*":                               http.StatusOK,
+		"*/*":                                  http.StatusOK,
+		"image/png, */*;q=0.1":                 http.StatusOK,
+		"text/html":                            http.StatusNotAcceptable,
+		"image/png, application/xml":           http.StatusNotAcceptable,
+		"application/json;q=0, text/csv;q=0.0": http.StatusNotAcceptable,
+	} {
+		recorder := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "http://localhost:3000/report", nil)
+		if err != nil {
+			t.Error(err)
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		n.ServeHTTP(recorder, req)
+		expect(t, recorder.Code, status)
+	}
+}
+
+func TestAcceptableWithoutRepresentations(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	n.Use(NewAcceptable())
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/report", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("Accept", "text/html")
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusOK)
+}
@@ -0,0 +1,101 @@
+package negroni
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrAsyncWriterClosed is returned by AsyncWriter.Write after Close has been called.
+var ErrAsyncWriterClosed = errors.New("negroni: async writer closed")
+
+// AsyncWriter is an io.Writer that hands writes to a background goroutine, which batches them
+// into fewer writes on the underlying writer. It trades strict ordering guarantees with other
+// writers for throughput, and is meant to be used as the output of a Logger:
+//
+//	w := negroni.NewAsyncWriter(os.Stdout, 1024)
+//	defer w.Close()
+//	l := negroni.NewLogger()
+//	l.Logger = log.New(w, "[negroni] ", 0)
+//
+// When the queue is full new records are dropped rather than blocking the request.
+type AsyncWriter struct {
+	w       io.Writer
+	records chan []byte
+	done    chan struct{}
+	dropped uint64
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewAsyncWriter returns a new AsyncWriter writing to w with room for queueSize pending records
+func NewAsyncWriter(w io.Writer, queueSize int) *AsyncWriter {
+	a := &AsyncWriter{
+		w:       w,
+		records: make(chan []byte, queueSize),
+		done:    make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+// Write queues a copy of p. It never blocks; when the queue is full the record is dropped
+// and counted.
+func (a *AsyncWriter) Write(p []byte) (int, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return 0, ErrAsyncWriterClosed
+	}
+
+	record := make([]byte, len(p))
+	copy(record, p)
+	select {
+	case a.records <- record:
+	default:
+		atomic.AddUint64(&a.dropped, 1)
+	}
+	return len(p), nil
+}
+
+// Dropped returns the number of records dropped because the queue was full.
+func (a *AsyncWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&a.dropped)
+}
+
+// Close stops accepting records and waits until all queued records have been written.
+func (a *AsyncWriter) Close() error {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.records)
+	}
+	a.mu.Unlock()
+
+	<-a.done
+	return nil
+}
+
+func (a *AsyncWriter) run() {
+	defer close(a.done)
+
+	var batch []byte
+	for record := range a.records {
+		batch = append(batch[:0], record...)
+	drain:
+		for {
+			select {
+			case record, ok := <-a.records:
+				if !ok {
+					break drain
+				}
+				batch = append(batch, record...)
+			default:
+				break drain
+			}
+		}
+		a.w.Write(batch)
+	}
+}
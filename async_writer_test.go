@@ -0,0 +1,68 @@
+package negroni
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type blockingWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	release chan struct{}
+}
+
+func (b *blockingWriter) Write(p []byte) (int, error) {
+	<-b.release
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func TestAsyncWriterFlushesAllRecords(t *testing.T) {
+	buff := bytes.NewBufferString("")
+	w := NewAsyncWriter(buff, 100)
+
+	l := NewLogger()
+	l.Logger = log.New(w, "[negroni] ", 0)
+
+	n := New()
+	n.Use(l)
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/foobar", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	for i := 0; i < 10; i++ {
+		n.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	expect(t, w.Close(), nil)
+	expect(t, strings.Count(buff.String(), "Started GET /foobar"), 10)
+	expect(t, strings.Count(buff.String(), "Completed"), 10)
+	expect(t, w.Dropped(), uint64(0))
+
+	_, err = w.Write([]byte("late"))
+	expect(t, err, ErrAsyncWriterClosed)
+}
+
+func TestAsyncWriterDropsWhenFull(t *testing.T) {
+	bw := &blockingWriter{release: make(chan struct{})}
+	w := NewAsyncWriter(bw, 2)
+
+	// The first record may be picked up by the background goroutine, which then
+	// blocks; at most two more fit in the queue.
+	for i := 0; i < 10; i++ {
+		w.Write([]byte("x\n"))
+	}
+	if w.Dropped() < 7 {
+		t.Errorf("Expected at least 7 dropped records, got %d", w.Dropped())
+	}
+
+	close(bw.release)
+	w.Close()
+	expect(t, uint64(strings.Count(bw.buf.String(), "x\n"))+w.Dropped(), uint64(10))
+}
@@ -0,0 +1,53 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+type authSchemeKey struct{}
+
+type authScheme struct {
+	scheme      string
+	credentials string
+}
+
+// AuthScheme returns the scheme, e.g. Bearer or Basic, and the credentials of the request's
+// Authorization header as parsed by the Authorization middleware. Both are empty if the
+// header is missing or malformed.
+func AuthScheme(ctx context.Context) (scheme, credentials string) {
+	a, ok := ctx.Value(authSchemeKey{}).(authScheme)
+	if !ok {
+		return "", ""
+	}
+	return a.scheme, a.credentials
+}
+
+// Authorization is a middleware handler that parses the Authorization header once into its
+// scheme and credentials for AuthScheme, so that several authentication middleware do not
+// each parse it again. It only parses; validating the credentials is left to them. The scheme
+// is canonicalized, so "bearer" and "BEARER" both become "Bearer".
+type Authorization struct{}
+
+// NewAuthorization returns a new instance of Authorization
+func NewAuthorization() *Authorization {
+	return &Authorization{}
+}
+
+func (a *Authorization) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	header := strings.TrimSpace(r.Header.Get("Authorization"))
+	i := strings.IndexAny(header, " \t")
+	if i <= 0 {
+		next(rw, r)
+		return
+	}
+	scheme, credentials := header[:i], strings.TrimSpace(header[i+1:])
+	if credentials == "" {
+		next(rw, r)
+		return
+	}
+
+	scheme = strings.ToUpper(scheme[:1]) + strings.ToLower(scheme[1:])
+	next(rw, r.WithContext(context.WithValue(r.Context(), authSchemeKey{}, authScheme{scheme, credentials})))
+}
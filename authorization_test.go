@@ -0,0 +1,35 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuthorization(t *testing.T) {
+	var scheme, credentials string
+
+	n := New()
+	n.Use(NewAuthorization())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		scheme, credentials = AuthScheme(r.Context())
+	})
+
+	for header, want := range map[string][2]string{
+		"Bearer eyJhbGciOi.payload.sig": {"Bearer", "eyJhbGciOi.payload.sig"},
+		"basic   dXNlcjpwYXNz":          {"Basic", "dXNlcjpwYXNz"},
+		"Bearer":                        {"", ""},
+		"Bearer ":                       {"", ""},
+		" token-without-scheme":         {"", ""},
+		"":                              {"", ""},
+	} {
+		req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+		if err != nil {
+			t.Error(err)
+		}
+		req.Header.Set("Authorization", header)
+		n.ServeHTTP(httptest.NewRecorder(), req)
+		expect(t, scheme, want[0])
+		expect(t, credentials, want[1])
+	}
+}
@@ -0,0 +1,58 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+type allowedMethodsKey struct{}
+
+// WithAllowedMethods returns a copy of ctx carrying the methods the matched route allows.
+// Routers call it so AutoOptions can answer OPTIONS requests.
+func WithAllowedMethods(ctx context.Context, methods ...string) context.Context {
+	return context.WithValue(ctx, allowedMethodsKey{}, methods)
+}
+
+// AllowedMethodsFromContext returns the methods stored with WithAllowedMethods, if any.
+func AllowedMethodsFromContext(ctx context.Context) ([]string, bool) {
+	methods, ok := ctx.Value(allowedMethodsKey{}).([]string)
+	return methods, ok
+}
+
+// AutoOptions is a middleware handler that answers OPTIONS requests with 204 No Content and an
+// Allow header built from the route's allowed methods in the context, without invoking the
+// rest of the chain. Requests without route information in the context are passed through.
+type AutoOptions struct{}
+
+// NewAutoOptions returns a new instance of AutoOptions
+func NewAutoOptions() *AutoOptions {
+	return &AutoOptions{}
+}
+
+func (o *AutoOptions) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if r.Method != "OPTIONS" {
+		next(rw, r)
+		return
+	}
+
+	methods, ok := AllowedMethodsFromContext(r.Context())
+	if !ok {
+		next(rw, r)
+		return
+	}
+
+	allow := append([]string(nil), methods...)
+	hasOptions := false
+	for _, m := range allow {
+		if m == "OPTIONS" {
+			hasOptions = true
+		}
+	}
+	if !hasOptions {
+		allow = append(allow, "OPTIONS")
+	}
+
+	rw.Header().Set("Allow", strings.Join(allow, ", "))
+	rw.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,48 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func serveAutoOptions(t *testing.T, method string, methods []string) (*httptest.ResponseRecorder, bool) {
+	recorder := httptest.NewRecorder()
+	called := false
+
+	n := New()
+	if methods != nil {
+		n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			next(rw, r.WithContext(WithAllowedMethods(r.Context(), methods...)))
+		})
+	}
+	n.Use(NewAutoOptions())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	req, err := http.NewRequest(method, "http://localhost:3000/users", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(recorder, req)
+	return recorder, called
+}
+
+func TestAutoOptionsWithRouteContext(t *testing.T) {
+	recorder, called := serveAutoOptions(t, "OPTIONS", []string{"GET", "POST"})
+	expect(t, called, false)
+	expect(t, recorder.Code, http.StatusNoContent)
+	expect(t, recorder.Header().Get("Allow"), "GET, POST, OPTIONS")
+}
+
+func TestAutoOptionsWithoutRouteContext(t *testing.T) {
+	recorder, called := serveAutoOptions(t, "OPTIONS", nil)
+	expect(t, called, true)
+	expect(t, recorder.Header().Get("Allow"), "")
+}
+
+func TestAutoOptionsOtherMethod(t *testing.T) {
+	_, called := serveAutoOptions(t, "GET", []string{"GET", "POST"})
+	expect(t, called, true)
+}
@@ -0,0 +1,83 @@
+package negroni
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// SchemaRule applies Validate to the bodies of requests with one of Methods and a Content-Type
+// of ContentType.
+type SchemaRule struct {
+	// Methods lists the request methods the rule applies to. Empty means any method.
+	Methods []string
+	// ContentType is the media type the rule applies to, e.g. application/json. Parameters
+	// such as charset are ignored when matching.
+	ContentType string
+	// Validate checks the request body.
+	Validate func(body []byte) error
+}
+
+func (rule SchemaRule) matches(r *http.Request) bool {
+	if len(rule.Methods) > 0 && !containsString(rule.Methods, r.Method) {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && strings.EqualFold(mediaType, rule.ContentType)
+}
+
+// BodySchema is a middleware handler that validates request bodies against the first matching
+// SchemaRule and rejects invalid ones with 400 Bad Request. Requests matching no rule, such as
+// form posts to a JSON schema rule or GETs, pass through untouched. Validated bodies are
+// buffered and handed on to the rest of the chain unchanged; bodies over MaxBytes are
+// rejected with 413 Request Entity Too Large instead.
+type BodySchema struct {
+	Rules []SchemaRule
+	// MaxBytes is the maximum size in bytes of a body to validate. Zero means no limit.
+	MaxBytes int64
+}
+
+// NewBodySchema returns a new instance of BodySchema with the given rules, buffering bodies
+// of up to 1MB
+func NewBodySchema(rules ...SchemaRule) *BodySchema {
+	return &BodySchema{
+		Rules:    rules,
+		MaxBytes: 1 << 20,
+	}
+}
+
+func (bs *BodySchema) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	for _, rule := range bs.Rules {
+		if !rule.matches(r) {
+			continue
+		}
+
+		var body []byte
+		if r.Body != nil {
+			src := r.Body
+			if bs.MaxBytes > 0 {
+				src = http.MaxBytesReader(rw, r.Body, bs.MaxBytes)
+			}
+			var err error
+			if body, err = ioutil.ReadAll(src); err != nil {
+				if isMaxBytesError(err) {
+					http.Error(rw, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+				} else {
+					http.Error(rw, "invalid request body", http.StatusBadRequest)
+				}
+				return
+			}
+			r.Body.Close()
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+		if err := rule.Validate(body); err != nil {
+			http.Error(rw, "invalid request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		break
+	}
+
+	next(rw, r)
+}
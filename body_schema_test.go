@@ -0,0 +1,95 @@
+package negroni
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBodySchema(t *testing.T) {
+	validated := 0
+	bs := NewBodySchema(SchemaRule{
+		Methods:     []string{"POST", "PUT"},
+		ContentType: "application/json",
+		Validate: func(body []byte) error {
+			validated++
+			var v struct {
+				Name string `json:"name"`
+			}
+			if err := json.Unmarshal(body, &v); err != nil {
+				return err
+			}
+			if v.Name == "" {
+				return errors.New("name is required")
+			}
+			return nil
+		},
+	})
+
+	var received string
+	n := New()
+	n.Use(bs)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Body != nil {
+			b, _ := ioutil.ReadAll(r.Body)
+			received = string(b)
+		}
+	})
+
+	serve := func(method, contentType, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req, err := http.NewRequest(method, "http://localhost:3000/users", strings.NewReader(body))
+		if err != nil {
+			t.Error(err)
+		}
+		req.Header.Set("Content-Type", contentType)
+		n.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	recorder := serve("POST", "application/json; charset=utf-8", `{"name":"ada"}`)
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, received, `{"name":"ada"}`)
+
+	recorder = serve("PUT", "application/json", `{}`)
+	expect(t, recorder.Code, http.StatusBadRequest)
+	expect(t, recorder.Body.String(), "invalid request body: name is required\n")
+	expect(t, validated, 2)
+
+	// Form posts and GETs are not validated.
+	recorder = serve("POST", "application/x-www-form-urlencoded", "name=")
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, received, "name=")
+	recorder = serve("GET", "application/json", "not json")
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, validated, 2)
+}
+
+func TestBodySchemaMaxBytes(t *testing.T) {
+	validated := false
+	bs := NewBodySchema(SchemaRule{
+		ContentType: "application/json",
+		Validate: func(body []byte) error {
+			validated = true
+			return nil
+		},
+	})
+	bs.MaxBytes = 8
+
+	n := New()
+	n.Use(bs)
+
+	recorder := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", "http://localhost:3000/users", strings.NewReader(`{"name":"ada lovelace"}`))
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusRequestEntityTooLarge)
+	expect(t, validated, false)
+}
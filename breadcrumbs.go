@@ -0,0 +1,47 @@
+package negroni
+
+import (
+	"context"
+	"sync"
+)
+
+// MaxBreadcrumbs is the number of breadcrumbs kept per request. Older breadcrumbs are
+// discarded first.
+var MaxBreadcrumbs = 32
+
+type breadcrumbsKey struct{}
+
+type breadcrumbs struct {
+	mu     sync.Mutex
+	crumbs []string
+}
+
+func withBreadcrumbs(ctx context.Context) context.Context {
+	return context.WithValue(ctx, breadcrumbsKey{}, &breadcrumbs{})
+}
+
+// AddBreadcrumb records what the handler is doing, so that Recovery can include it in the
+// report if the handler panics. It does nothing unless a Recovery is in the stack.
+func AddBreadcrumb(ctx context.Context, crumb string) {
+	b, ok := ctx.Value(breadcrumbsKey{}).(*breadcrumbs)
+	if !ok {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.crumbs = append(b.crumbs, crumb)
+	if over := len(b.crumbs) - MaxBreadcrumbs; over > 0 {
+		b.crumbs = append(b.crumbs[:0], b.crumbs[over:]...)
+	}
+}
+
+// Breadcrumbs returns the breadcrumbs added for the request ctx belongs to, oldest first.
+func Breadcrumbs(ctx context.Context) []string {
+	b, ok := ctx.Value(breadcrumbsKey{}).(*breadcrumbs)
+	if !ok {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.crumbs...)
+}
@@ -0,0 +1,57 @@
+package negroni
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBreadcrumbsInRecovery(t *testing.T) {
+	buff := bytes.NewBufferString("")
+	var reported []string
+
+	rec := NewRecovery()
+	rec.Logger = log.New(buff, "[negroni] ", 0)
+	rec.PanicHandler = func(r *http.Request, err interface{}, stack []byte) {
+		reported = Breadcrumbs(r.Context())
+	}
+
+	n := New()
+	n.Use(rec)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		AddBreadcrumb(r.Context(), "loading user 42")
+		AddBreadcrumb(r.Context(), "rendering profile")
+		panic("here is a panic!")
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !strings.Contains(buff.String(), "Breadcrumbs:\n  loading user 42\n  rendering profile") {
+		t.Errorf("Expected breadcrumbs in recovery log, got %q", buff.String())
+	}
+	expect(t, len(reported), 2)
+}
+
+func TestBreadcrumbsCapped(t *testing.T) {
+	old := MaxBreadcrumbs
+	defer func() { MaxBreadcrumbs = old }()
+	MaxBreadcrumbs = 3
+
+	ctx := withBreadcrumbs(context.Background())
+	for i := 0; i < 5; i++ {
+		AddBreadcrumb(ctx, fmt.Sprint(i))
+	}
+	expect(t, strings.Join(Breadcrumbs(ctx), ","), "2,3,4")
+
+	AddBreadcrumb(context.Background(), "ignored")
+	expect(t, len(Breadcrumbs(context.Background())), 0)
+}
@@ -0,0 +1,35 @@
+package negroni
+
+import (
+	"net/http"
+	"time"
+)
+
+// BuildInfo is a middleware handler that adds deployment metadata headers to every response,
+// set just before the response is written, so clients and monitoring can tell which build
+// served a request, e.g. during a canary rollout.
+type BuildInfo struct {
+	// Headers are the response headers to set and their values.
+	Headers map[string]string
+}
+
+// NewBuildInfo returns a new instance of BuildInfo setting X-App-Version to version and
+// X-Deploy-Time to deployed in RFC 3339 format. A zero deployed time is left out.
+func NewBuildInfo(version string, deployed time.Time) *BuildInfo {
+	headers := map[string]string{"X-App-Version": version}
+	if !deployed.IsZero() {
+		headers["X-Deploy-Time"] = deployed.UTC().Format(time.RFC3339)
+	}
+	return &BuildInfo{Headers: headers}
+}
+
+func (b *BuildInfo) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	rw.(ResponseWriter).Before(func(rw ResponseWriter) {
+		h := rw.Header()
+		for k, v := range b.Headers {
+			h.Set(k, v)
+		}
+	})
+
+	next(rw, r)
+}
@@ -0,0 +1,43 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBuildInfo(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	deployed := time.Date(2015, 6, 1, 12, 30, 0, 0, time.UTC)
+
+	n := New()
+	n.Use(NewBuildInfo("1.4.2", deployed))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusCreated)
+	})
+
+	n.ServeHTTP(recorder, (*http.Request)(nil))
+	expect(t, recorder.Code, http.StatusCreated)
+	expect(t, recorder.Header().Get("X-App-Version"), "1.4.2")
+	expect(t, recorder.Header().Get("X-Deploy-Time"), "2015-06-01T12:30:00Z")
+}
+
+func TestBuildInfoCustomHeaders(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	b := NewBuildInfo("1.4.2", time.Time{})
+	b.Headers["X-Commit"] = "3f2a9c1"
+
+	n := New()
+	n.Use(b)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte("ok"))
+	})
+
+	n.ServeHTTP(recorder, (*http.Request)(nil))
+	expect(t, recorder.Header().Get("X-App-Version"), "1.4.2")
+	expect(t, recorder.Header().Get("X-Commit"), "3f2a9c1")
+	_, ok := recorder.Header()["X-Deploy-Time"]
+	expect(t, ok, false)
+}
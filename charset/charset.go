@@ -0,0 +1,171 @@
+// Package charset provides a Negroni middleware handler that transcodes request and response
+// bodies between UTF-8 and legacy character sets. It lives in a package of its own because it
+// depends on golang.org/x/text, while the negroni package itself only uses the standard
+// library.
+package charset
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/codegangsta/negroni"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/transform"
+)
+
+// Charset is a middleware handler that lets handlers work in UTF-8 only while serving clients
+// that use legacy character sets. Request bodies declaring another charset in Content-Type,
+// e.g. "text/plain; charset=iso-8859-1", are transcoded to UTF-8 and their Content-Type is
+// changed to say so; bodies in charsets that are not known are rejected with 415 Unsupported
+// Media Type. Charset names are looked up as browsers do, see
+// golang.org/x/text/encoding/htmlindex.
+//
+// With TranscodeResponses set, textual responses are transcoded to the charset the client
+// prefers in Accept-Charset as well. Characters that charset cannot represent are replaced.
+type Charset struct {
+	// TranscodeResponses transcodes text, JSON and XML responses written in UTF-8 to the
+	// charset preferred by the client.
+	TranscodeResponses bool
+}
+
+// New returns a new instance of Charset transcoding request bodies only
+func New() *Charset {
+	return &Charset{}
+}
+
+func (c *Charset) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if r.Body != nil {
+		mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if name := params["charset"]; err == nil && !utf8Charset(name) {
+			enc, err := htmlindex.Get(name)
+			if err != nil {
+				http.Error(rw, "unsupported charset "+strconv.Quote(name), http.StatusUnsupportedMediaType)
+				return
+			}
+			params["charset"] = "utf-8"
+			r.Header.Set("Content-Type", mime.FormatMediaType(mediaType, params))
+			r.Header.Del("Content-Length")
+			r.ContentLength = -1
+			r.Body = readCloser{transform.NewReader(r.Body, enc.NewDecoder()), r.Body}
+		}
+	}
+
+	if !c.TranscodeResponses {
+		next(rw, r)
+		return
+	}
+	name, enc := preferredCharset(r.Header.Get("Accept-Charset"))
+	if enc == nil {
+		next(rw, r)
+		return
+	}
+
+	cw := &charsetWriter{ResponseWriter: rw.(negroni.ResponseWriter), charset: name, encoding: enc}
+	next(cw, r)
+	if cw.w != nil {
+		cw.w.Close()
+	}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// preferredCharset returns the charset the client prefers in an Accept-Charset header, or a
+// nil encoding if that is UTF-8 or none of the acceptable charsets is known.
+func preferredCharset(header string) (string, encoding.Encoding) {
+	type candidate struct {
+		name string
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(fields[0]))
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{name, q})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	for _, c := range candidates {
+		if c.name == "*" || utf8Charset(c.name) {
+			return "", nil
+		}
+		if enc, err := htmlindex.Get(c.name); err == nil {
+			return c.name, enc
+		}
+	}
+	return "", nil
+}
+
+func utf8Charset(name string) bool {
+	switch strings.ToLower(name) {
+	case "", "utf-8", "utf8", "us-ascii":
+		return true
+	}
+	return false
+}
+
+// textualType reports whether responses of the given media type are text worth transcoding.
+func textualType(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "text/") || mediaType == "application/json" ||
+		mediaType == "application/xml" || strings.HasSuffix(mediaType, "+json") ||
+		strings.HasSuffix(mediaType, "+xml")
+}
+
+// charsetWriter transcodes the response body from UTF-8 to charset once the response turns
+// out to be textual.
+type charsetWriter struct {
+	negroni.ResponseWriter
+	charset  string
+	encoding encoding.Encoding
+	decided  bool
+	w        io.WriteCloser
+}
+
+func (cw *charsetWriter) WriteHeader(s int) {
+	if !cw.decided {
+		cw.decided = true
+		h := cw.Header()
+		mediaType, params, err := mime.ParseMediaType(h.Get("Content-Type"))
+		if err == nil && textualType(mediaType) && utf8Charset(params["charset"]) {
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params["charset"] = cw.charset
+			h.Set("Content-Type", mime.FormatMediaType(mediaType, params))
+			h.Del("Content-Length")
+			cw.w = transform.NewWriter(cw.ResponseWriter, encoding.ReplaceUnsupported(cw.encoding.NewEncoder()))
+		}
+	}
+	cw.ResponseWriter.WriteHeader(s)
+}
+
+func (cw *charsetWriter) Write(p []byte) (int, error) {
+	if !cw.decided {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.w != nil {
+		return cw.w.Write(p)
+	}
+	return cw.ResponseWriter.Write(p)
+}
@@ -0,0 +1,95 @@
+package charset
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/codegangsta/negroni"
+)
+
+func expect(t *testing.T, a interface{}, b interface{}) {
+	if a != b {
+		t.Errorf("Expected %v (type %v) - Got %v (type %v)", b, reflect.TypeOf(b), a, reflect.TypeOf(a))
+	}
+}
+
+func TestCharsetRequestLatin1(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	var body, contentType string
+
+	n := negroni.New()
+	n.Use(New())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		expect(t, err, nil)
+		body, contentType = string(b), r.Header.Get("Content-Type")
+	})
+
+	// "café" in ISO-8859-1.
+	req, err := http.NewRequest("POST", "http://localhost:3000/", bytes.NewReader([]byte{'c', 'a', 'f', 0xe9}))
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=ISO-8859-1")
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, body, "café")
+	expect(t, contentType, "text/plain; charset=utf-8")
+	expect(t, req.ContentLength, int64(-1))
+}
+
+func TestCharsetRequestUnknown(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	n := negroni.New()
+	n.Use(New())
+
+	req, err := http.NewRequest("POST", "http://localhost:3000/", bytes.NewReader([]byte("x")))
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=klingon")
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusUnsupportedMediaType)
+}
+
+func TestCharsetResponse(t *testing.T) {
+	c := New()
+	c.TranscodeResponses = true
+
+	n := negroni.New()
+	n.Use(c)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/image" {
+			rw.Header().Set("Content-Type", "image/png")
+		} else {
+			rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		}
+		rw.Write([]byte("café"))
+	})
+
+	for _, c := range []struct {
+		path, acceptCharset, contentType, body string
+	}{
+		{"/", "iso-8859-1, utf-8;q=0.5", "text/plain; charset=iso-8859-1", "caf\xe9"},
+		{"/", "utf-8, iso-8859-1;q=0.5", "text/plain; charset=utf-8", "café"},
+		{"/", "", "text/plain; charset=utf-8", "café"},
+		{"/image", "iso-8859-1", "image/png", "café"},
+	} {
+		recorder := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "http://localhost:3000"+c.path, nil)
+		if err != nil {
+			t.Error(err)
+		}
+		if c.acceptCharset != "" {
+			req.Header.Set("Accept-Charset", c.acceptCharset)
+		}
+		n.ServeHTTP(recorder, req)
+		expect(t, recorder.Header().Get("Content-Type"), c.contentType)
+		expect(t, recorder.Body.String(), c.body)
+	}
+}
@@ -0,0 +1,184 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of a CircuitBreaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets requests through while counting their results.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects requests until the cooldown has passed.
+	CircuitOpen
+	// CircuitHalfOpen lets a single probe request through to test the dependency.
+	CircuitHalfOpen
+)
+
+type circuitBreakersKey struct{}
+
+// RecordResult reports the outcome of a call to a dependency to every CircuitBreaker guarding
+// the request ctx belongs to. A nil err counts as a success.
+func RecordResult(ctx context.Context, err error) {
+	breakers, _ := ctx.Value(circuitBreakersKey{}).([]*CircuitBreaker)
+	for _, cb := range breakers {
+		cb.record(err)
+	}
+}
+
+// CircuitBreaker is a middleware handler that protects a dependency from cascading failures.
+// Handlers report their calls to the dependency with RecordResult; once the error rate within
+// Window reaches Threshold the circuit opens and requests are rejected with 503 until Cooldown
+// has passed. A single probe request is then let through (half-open): if it reports success
+// the circuit closes, if it reports an error the circuit opens again.
+type CircuitBreaker struct {
+	// Name identifies the dependency.
+	Name string
+	// Threshold is the error rate, between 0 and 1, that opens the circuit.
+	Threshold float64
+	// MinRequests is the number of results needed within Window before the circuit may open.
+	MinRequests int
+	// Window is the period over which results are counted.
+	Window time.Duration
+	// Cooldown is how long the circuit stays open before a probe request is let through.
+	Cooldown time.Duration
+	// Healthy optionally reports the health of the dependency; when it returns false the
+	// circuit opens without waiting for errors.
+	Healthy func() bool
+	// Now returns the current time and may be replaced in tests.
+	Now func() time.Time
+
+	mu          sync.Mutex
+	state       CircuitState
+	successes   int
+	failures    int
+	windowStart time.Time
+	openedAt    time.Time
+	probing     bool
+}
+
+// NewCircuitBreaker returns a new instance of CircuitBreaker for the named dependency, opening
+// at a 50% error rate over at least 10 requests per 10 seconds and cooling down for 5 seconds
+func NewCircuitBreaker(name string) *CircuitBreaker {
+	return &CircuitBreaker{
+		Name:        name,
+		Threshold:   0.5,
+		MinRequests: 10,
+		Window:      10 * time.Second,
+		Cooldown:    5 * time.Second,
+		Now:         time.Now,
+	}
+}
+
+// State returns the current state of the circuit.
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.advance(cb.Now())
+	return cb.state
+}
+
+func (cb *CircuitBreaker) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	probe, ok := cb.allow()
+	if !ok {
+		http.Error(rw, cb.Name+" is unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if probe {
+		// Release the probe even if the handler panics, or the circuit would reject
+		// every request from then on.
+		defer func() {
+			cb.mu.Lock()
+			cb.probing = false
+			cb.mu.Unlock()
+		}()
+	}
+
+	breakers, _ := r.Context().Value(circuitBreakersKey{}).([]*CircuitBreaker)
+	breakers = append(breakers[:len(breakers):len(breakers)], cb)
+	next(rw, r.WithContext(context.WithValue(r.Context(), circuitBreakersKey{}, breakers)))
+}
+
+// allow reports whether a request may pass and whether it is the half-open probe.
+func (cb *CircuitBreaker) allow() (probe bool, ok bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := cb.Now()
+	if cb.Healthy != nil && !cb.Healthy() {
+		cb.open(now)
+		return false, false
+	}
+	cb.advance(now)
+
+	switch cb.state {
+	case CircuitOpen:
+		return false, false
+	case CircuitHalfOpen:
+		if cb.probing {
+			return false, false
+		}
+		cb.probing = true
+		return true, true
+	}
+	return false, true
+}
+
+func (cb *CircuitBreaker) record(err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := cb.Now()
+	cb.advance(now)
+	switch cb.state {
+	case CircuitHalfOpen:
+		if err != nil {
+			cb.open(now)
+		} else {
+			cb.close(now)
+		}
+	case CircuitClosed:
+		if err != nil {
+			cb.failures++
+		} else {
+			cb.successes++
+		}
+		total := cb.successes + cb.failures
+		if total >= cb.MinRequests && float64(cb.failures)/float64(total) >= cb.Threshold {
+			cb.open(now)
+		}
+	}
+}
+
+// advance moves the circuit from open to half-open after the cooldown and starts a new
+// counting window when the current one has passed.
+func (cb *CircuitBreaker) advance(now time.Time) {
+	switch cb.state {
+	case CircuitOpen:
+		if now.Sub(cb.openedAt) >= cb.Cooldown {
+			cb.state = CircuitHalfOpen
+			cb.probing = false
+		}
+	case CircuitClosed:
+		if now.Sub(cb.windowStart) >= cb.Window {
+			cb.windowStart = now
+			cb.successes, cb.failures = 0, 0
+		}
+	}
+}
+
+func (cb *CircuitBreaker) open(now time.Time) {
+	cb.state = CircuitOpen
+	cb.openedAt = now
+	cb.successes, cb.failures = 0, 0
+}
+
+func (cb *CircuitBreaker) close(now time.Time) {
+	cb.state = CircuitClosed
+	cb.windowStart = now
+	cb.successes, cb.failures = 0, 0
+}
@@ -0,0 +1,117 @@
+package negroni
+
+import (
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerStates(t *testing.T) {
+	now := time.Unix(1000, 0)
+	cb := NewCircuitBreaker("db")
+	cb.MinRequests = 4
+	cb.Now = func() time.Time { return now }
+
+	var result error
+	n := New()
+	n.Use(cb)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		RecordResult(r.Context(), result)
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	serve := func() int {
+		recorder := httptest.NewRecorder()
+		n.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	// Closed: two successes and two failures reach the 50% threshold.
+	expect(t, serve(), http.StatusOK)
+	expect(t, serve(), http.StatusOK)
+	result = errors.New("timeout")
+	expect(t, serve(), http.StatusOK)
+	expect(t, cb.State(), CircuitClosed)
+	expect(t, serve(), http.StatusOK)
+	expect(t, cb.State(), CircuitOpen)
+
+	// Open: requests are rejected until the cooldown passes.
+	expect(t, serve(), http.StatusServiceUnavailable)
+	now = now.Add(5 * time.Second)
+	expect(t, cb.State(), CircuitHalfOpen)
+
+	// Half-open: a failing probe opens the circuit again.
+	expect(t, serve(), http.StatusOK)
+	expect(t, cb.State(), CircuitOpen)
+
+	// A successful probe closes it.
+	now = now.Add(5 * time.Second)
+	result = nil
+	expect(t, serve(), http.StatusOK)
+	expect(t, cb.State(), CircuitClosed)
+	expect(t, serve(), http.StatusOK)
+}
+
+func TestCircuitBreakerHealthPredicate(t *testing.T) {
+	healthy := false
+	cb := NewCircuitBreaker("cache")
+	cb.Healthy = func() bool { return healthy }
+
+	n := New()
+	n.Use(cb)
+
+	recorder := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusServiceUnavailable)
+	expect(t, recorder.Body.String(), "cache is unavailable\n")
+	expect(t, cb.State(), CircuitOpen)
+}
+
+func TestCircuitBreakerProbePanic(t *testing.T) {
+	now := time.Unix(1000, 0)
+	cb := NewCircuitBreaker("db")
+	cb.Now = func() time.Time { return now }
+	cb.open(now)
+	now = now.Add(5 * time.Second)
+
+	rec := NewRecovery()
+	rec.Logger = log.New(ioutil.Discard, "", 0)
+
+	panics := true
+	n := New()
+	n.Use(rec)
+	n.Use(cb)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if panics {
+			panic("probe failed")
+		}
+		RecordResult(r.Context(), nil)
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	recorder := httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusInternalServerError)
+	expect(t, cb.State(), CircuitHalfOpen)
+
+	// The probe was released, so the next request probes again.
+	panics = false
+	recorder = httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, cb.State(), CircuitClosed)
+}
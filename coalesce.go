@@ -0,0 +1,150 @@
+package negroni
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// Coalesce is a middleware handler that shares the result of a request with retries of it
+// that arrive while it is still running. Requests carry a key in Header, such as an
+// idempotency key; while a request with the same method, path, caller and key is in flight,
+// further ones wait for it to finish and receive a copy of its response, without its
+// Set-Cookie headers, instead of running the handler again. Nothing is kept once the request
+// completes, so later retries run normally.
+type Coalesce struct {
+	// Header is the request header carrying the key. Requests without it are not coalesced.
+	Header string
+	// Key optionally returns the key requests are coalesced by, or "" to not coalesce a
+	// request. It must tell callers apart, since waiters get the response of whoever came
+	// first. When nil, the key combines the method, path, Authorization header, or the
+	// client IP without one, and the value of Header.
+	Key func(r *http.Request) string
+
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// flight is a request in progress and, once done is closed, its response. A status of 0
+// means the handler panicked or aborted before writing a response.
+type flight struct {
+	done   chan struct{}
+	status int
+	header http.Header
+	body   bytes.Buffer
+}
+
+// NewCoalesce returns a new instance of Coalesce keyed on the Idempotency-Key header
+func NewCoalesce() *Coalesce {
+	return &Coalesce{Header: "Idempotency-Key"}
+}
+
+func (c *Coalesce) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	key := c.key(r)
+	if key == "" {
+		next(rw, r)
+		return
+	}
+
+	c.mu.Lock()
+	if f, ok := c.flights[key]; ok {
+		c.mu.Unlock()
+		c.wait(rw, r, f)
+		return
+	}
+	f := &flight{done: make(chan struct{})}
+	if c.flights == nil {
+		c.flights = make(map[string]*flight)
+	}
+	c.flights[key] = f
+	c.mu.Unlock()
+
+	res := rw.(ResponseWriter)
+	returned := false
+	defer func() {
+		if returned && f.status == 0 {
+			// The handler wrote nothing, which is an implicit 200.
+			f.status = http.StatusOK
+			f.header = cloneHeader(res.Header())
+		}
+		c.mu.Lock()
+		delete(c.flights, key)
+		c.mu.Unlock()
+		close(f.done)
+	}()
+
+	res.Before(func(rw ResponseWriter) {
+		f.status = rw.Status()
+		f.header = cloneHeader(rw.Header())
+	})
+	next(&flightWriter{res, f}, r)
+	returned = true
+}
+
+func (c *Coalesce) key(r *http.Request) string {
+	if c.Key != nil {
+		return c.Key(r)
+	}
+	id := r.Header.Get(c.Header)
+	if id == "" {
+		return ""
+	}
+	caller := r.Header.Get("Authorization")
+	if caller == "" {
+		caller = clientIP(r, false)
+	}
+	return r.Method + " " + r.URL.Path + " " + caller + " " + id
+}
+
+// wait serves r with the response of f once it is done.
+func (c *Coalesce) wait(rw http.ResponseWriter, r *http.Request, f *flight) {
+	select {
+	case <-f.done:
+	case <-r.Context().Done():
+		return
+	}
+
+	if f.status == 0 {
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	for k, v := range f.header {
+		if k == "Set-Cookie" {
+			// Cookies are meant for the first caller only.
+			continue
+		}
+		rw.Header()[k] = append([]string(nil), v...)
+	}
+	rw.WriteHeader(f.status)
+	rw.Write(f.body.Bytes())
+}
+
+// flightWriter copies the response body into its flight.
+type flightWriter struct {
+	ResponseWriter
+	f *flight
+}
+
+func (w *flightWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.f.body.Write(b[:n])
+	return n, err
+}
+
+func (w *flightWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return hijack(w.ResponseWriter)
+}
+
+func (w *flightWriter) CloseNotify() <-chan bool {
+	return closeNotify(w.ResponseWriter)
+}
+
+func cloneHeader(h http.Header) http.Header {
+	c := make(http.Header, len(h))
+	for k, v := range h {
+		c[k] = append([]string(nil), v...)
+	}
+	return c
+}
@@ -0,0 +1,168 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCoalesceConcurrentRetries(t *testing.T) {
+	var runs int32
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	retried := make(chan struct{})
+
+	c := NewCoalesce()
+	c.Key = func(r *http.Request) string {
+		if r.Header.Get("X-Retry") != "" {
+			close(retried)
+		}
+		return r.Method + " " + r.URL.Path + " " + r.Header.Get(c.Header)
+	}
+	n := New()
+	n.Use(c)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&runs, 1)
+		close(entered)
+		<-release
+		rw.Header().Set("X-Order", "42")
+		rw.Header().Set("Set-Cookie", "session=first")
+		rw.WriteHeader(http.StatusCreated)
+		rw.Write([]byte("order created"))
+	})
+
+	serve := func(retry bool) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req, err := http.NewRequest("POST", "http://localhost:3000/orders", nil)
+		if err != nil {
+			t.Error(err)
+		}
+		req.Header.Set("Idempotency-Key", "abc")
+		if retry {
+			req.Header.Set("X-Retry", "1")
+		}
+		n.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	var wg sync.WaitGroup
+	recorders := make([]*httptest.ResponseRecorder, 2)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		recorders[0] = serve(false)
+	}()
+	<-entered
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		recorders[1] = serve(true)
+	}()
+	// Release the first request once the retry has had time to join it.
+	<-retried
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	expect(t, atomic.LoadInt32(&runs), int32(1))
+	for _, recorder := range recorders {
+		expect(t, recorder.Code, http.StatusCreated)
+		expect(t, recorder.Body.String(), "order created")
+		expect(t, recorder.Header().Get("X-Order"), "42")
+	}
+	expect(t, recorders[0].Header().Get("Set-Cookie"), "session=first")
+	expect(t, recorders[1].Header().Get("Set-Cookie"), "")
+
+	// Once the request has completed, a retry runs again.
+	entered = make(chan struct{})
+	release = make(chan struct{})
+	close(release)
+	serve(false)
+	expect(t, atomic.LoadInt32(&runs), int32(2))
+}
+
+func TestCoalesceKeySeparatesCallers(t *testing.T) {
+	c := NewCoalesce()
+	key := func(auth, remoteAddr, id string) string {
+		req, err := http.NewRequest("POST", "http://localhost:3000/orders", nil)
+		if err != nil {
+			t.Error(err)
+		}
+		req.RemoteAddr = remoteAddr
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		if id != "" {
+			req.Header.Set("Idempotency-Key", id)
+		}
+		return c.key(req)
+	}
+
+	expect(t, key("Bearer a", "10.0.0.1:1", ""), "")
+	expect(t, key("Bearer a", "10.0.0.1:1", "abc"), key("Bearer a", "10.0.0.2:1", "abc"))
+	refute(t, key("Bearer a", "10.0.0.1:1", "abc"), key("Bearer b", "10.0.0.1:1", "abc"))
+	refute(t, key("", "10.0.0.1:1", "abc"), key("", "10.0.0.2:1", "abc"))
+}
+
+func TestCoalesceImplicitOK(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	retried := make(chan struct{})
+
+	c := NewCoalesce()
+	c.Key = func(r *http.Request) string {
+		if r.Header.Get("X-Retry") != "" {
+			close(retried)
+		}
+		return r.Header.Get(c.Header)
+	}
+	n := New()
+	n.Use(c)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-release
+		rw.Header().Set("X-Order", "42")
+	})
+
+	serve := func(retry bool) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req, err := http.NewRequest("POST", "http://localhost:3000/orders", nil)
+		if err != nil {
+			t.Error(err)
+		}
+		req.Header.Set("Idempotency-Key", "abc")
+		if retry {
+			req.Header.Set("X-Retry", "1")
+		}
+		n.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	var wg sync.WaitGroup
+	var first *httptest.ResponseRecorder
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		first = serve(false)
+	}()
+	<-entered
+
+	var second *httptest.ResponseRecorder
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		second = serve(true)
+	}()
+	<-retried
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	expect(t, first.Code, http.StatusOK)
+	expect(t, second.Code, http.StatusOK)
+	expect(t, second.Header().Get("X-Order"), "42")
+}
@@ -0,0 +1,200 @@
+package negroni
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net"
+	"net/http"
+)
+
+// Compress is a middleware handler that compresses responses with gzip or deflate when the
+// client accepts it, using the coding chosen by AcceptEncoding if it is in the stack and
+// negotiating one from Accept-Encoding otherwise. Responses that already have a
+// Content-Encoding, have no body or are smaller than MinSize are sent as is. Compressed
+// responses lose their Content-Length. All responses get "Vary: Accept-Encoding".
+//
+// Handlers further down the stack get a ResponseWriter whose Status, Written and Size refer to
+// the uncompressed response, and which supports Flush and Hijack. Flushing before MinSize bytes
+// have been written commits to sending the response uncompressed, which suits streams of small
+// events.
+type Compress struct {
+	// Level is the compression level, from gzip.BestSpeed to gzip.BestCompression, or
+	// gzip.DefaultCompression.
+	Level int
+	// MinSize is the smallest response body, in bytes, worth compressing.
+	MinSize int
+}
+
+// NewCompress returns a new instance of Compress using the given level and compressing
+// responses of 1KB or more
+func NewCompress(level int) *Compress {
+	return &Compress{Level: level, MinSize: 1024}
+}
+
+func (c *Compress) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	// The response depends on Accept-Encoding whether or not this one gets compressed.
+	rw.Header().Add("Vary", "Accept-Encoding")
+
+	enc, ok := EncodingFromContext(r.Context())
+	if !ok {
+		enc, _ = NegotiateEncoding(r.Header.Get("Accept-Encoding"), "gzip", "deflate")
+	}
+	if enc != "gzip" && enc != "deflate" {
+		next(rw, r)
+		return
+	}
+
+	cw := &compressWriter{rw: rw.(ResponseWriter), encoding: enc, level: c.Level, minSize: c.MinSize}
+	next(cw, r)
+	cw.close()
+}
+
+// compressWriter holds back the start of the response until it knows whether it is worth
+// compressing, then either compresses it or passes it through.
+type compressWriter struct {
+	rw       ResponseWriter
+	encoding string
+	level    int
+	minSize  int
+
+	status  int
+	size    int
+	buf     bytes.Buffer
+	decided bool
+	zw      io.WriteCloser
+}
+
+func (w *compressWriter) Header() http.Header {
+	return w.rw.Header()
+}
+
+func (w *compressWriter) WriteHeader(s int) {
+	if w.status != 0 {
+		return
+	}
+	if s >= 100 && s < 200 && s != http.StatusSwitchingProtocols {
+		// Informational responses such as 103 Early Hints precede the final one.
+		w.rw.WriteHeader(s)
+		return
+	}
+	w.status = s
+	if !bodyAllowed(s) {
+		w.decide(false)
+	}
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	w.size += len(p)
+	if !w.decided {
+		w.buf.Write(p)
+		if w.buf.Len() >= w.minSize {
+			if err := w.decide(true); err != nil {
+				return 0, err
+			}
+		}
+		return len(p), nil
+	}
+	if w.zw != nil {
+		return w.zw.Write(p)
+	}
+	return w.rw.Write(p)
+}
+
+func (w *compressWriter) Status() int {
+	return w.status
+}
+
+func (w *compressWriter) Written() bool {
+	return w.status != 0
+}
+
+func (w *compressWriter) Size() int {
+	return w.size
+}
+
+func (w *compressWriter) Before(before func(ResponseWriter)) {
+	w.rw.Before(before)
+}
+
+func (w *compressWriter) Flush() {
+	if w.status == 0 {
+		return
+	}
+	if !w.decided {
+		w.decide(w.buf.Len() >= w.minSize)
+	}
+	if f, ok := w.zw.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+	w.rw.Flush()
+}
+
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return hijack(w.rw)
+}
+
+func (w *compressWriter) CloseNotify() <-chan bool {
+	return closeNotify(w.rw)
+}
+
+// decide commits the status and headers, compressing the rest of the response if compress is
+// set and the handler has not encoded it already, and writes out what was held back.
+func (w *compressWriter) decide(compress bool) error {
+	w.decided = true
+	h := w.rw.Header()
+	if compress && h.Get("Content-Encoding") == "" {
+		h.Set("Content-Encoding", w.encoding)
+		h.Del("Content-Length")
+		w.zw = w.newEncoder()
+	}
+	w.rw.WriteHeader(w.status)
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	var err error
+	if w.zw != nil {
+		_, err = w.zw.Write(w.buf.Bytes())
+	} else {
+		_, err = w.rw.Write(w.buf.Bytes())
+	}
+	w.buf.Reset()
+	return err
+}
+
+func (w *compressWriter) newEncoder() io.WriteCloser {
+	if w.encoding == "deflate" {
+		// The deflate content coding is the zlib format, not raw DEFLATE (RFC 9110 8.4.1.2).
+		if zw, err := zlib.NewWriterLevel(w.rw, w.level); err == nil {
+			return zw
+		}
+		return zlib.NewWriter(w.rw)
+	}
+	if zw, err := gzip.NewWriterLevel(w.rw, w.level); err == nil {
+		return zw
+	}
+	return gzip.NewWriter(w.rw)
+}
+
+// close sends whatever is still held back and finishes the compressed stream.
+func (w *compressWriter) close() {
+	if w.status == 0 {
+		return
+	}
+	if !w.decided {
+		w.decide(false)
+	}
+	if w.zw != nil {
+		w.zw.Close()
+	}
+}
+
+// bodyAllowed reports whether a response with the given status may have a body.
+func bodyAllowed(status int) bool {
+	return status >= 200 && status != http.StatusNoContent && status != http.StatusNotModified
+}
@@ -0,0 +1,211 @@
+package negroni
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func serveCompress(t *testing.T, c *Compress, acceptEncoding string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	n.Use(c)
+	n.UseHandlerFunc(handler)
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	n.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestCompressGzip(t *testing.T) {
+	body := strings.Repeat("negroni ", 1000)
+	var status, size int
+
+	recorder := serveCompress(t, NewCompress(gzip.BestSpeed), "gzip, deflate", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Length", "8000")
+		rw.WriteHeader(http.StatusCreated)
+		for i := 0; i < 1000; i++ {
+			rw.Write([]byte("negroni "))
+		}
+		status, size = rw.(ResponseWriter).Status(), rw.(ResponseWriter).Size()
+	})
+	expect(t, status, http.StatusCreated)
+	expect(t, size, len(body))
+	expect(t, recorder.Code, http.StatusCreated)
+	expect(t, recorder.Header().Get("Content-Encoding"), "gzip")
+	expect(t, recorder.Header().Get("Content-Length"), "")
+	expect(t, recorder.Header().Get("Vary"), "Accept-Encoding")
+
+	zr, err := gzip.NewReader(recorder.Body)
+	expect(t, err, nil)
+	got, err := ioutil.ReadAll(zr)
+	expect(t, err, nil)
+	expect(t, string(got), body)
+}
+
+func TestCompressDeflate(t *testing.T) {
+	body := strings.Repeat("a", 2048)
+
+	recorder := serveCompress(t, NewCompress(zlib.DefaultCompression), "deflate", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(body))
+	})
+	expect(t, recorder.Header().Get("Content-Encoding"), "deflate")
+	zr, err := zlib.NewReader(recorder.Body)
+	expect(t, err, nil)
+	got, err := ioutil.ReadAll(zr)
+	expect(t, err, nil)
+	expect(t, string(got), body)
+}
+
+func TestCompressInformational(t *testing.T) {
+	body := strings.Repeat("a", 2048)
+
+	n := New()
+	n.Use(NewCompress(gzip.DefaultCompression))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Link", "</style.css>; rel=preload")
+		rw.WriteHeader(http.StatusEarlyHints)
+		rw.WriteHeader(http.StatusCreated)
+		rw.Write([]byte(body))
+		expect(t, rw.(ResponseWriter).Status(), http.StatusCreated)
+	})
+	// The recorder does not tell informational responses apart, so use a real server.
+	server := httptest.NewServer(n)
+	defer server.Close()
+
+	req, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("Accept-Encoding", "gzip")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	expect(t, res.StatusCode, http.StatusCreated)
+	expect(t, res.Header.Get("Content-Encoding"), "gzip")
+	zr, err := gzip.NewReader(res.Body)
+	expect(t, err, nil)
+	got, err := ioutil.ReadAll(zr)
+	expect(t, err, nil)
+	expect(t, string(got), body)
+}
+
+func TestCompressSkipped(t *testing.T) {
+	large := strings.Repeat("a", 2048)
+
+	for _, c := range []struct {
+		name           string
+		acceptEncoding string
+		encoding       string
+		body           string
+	}{
+		{"not accepted", "", "", large},
+		{"below MinSize", "gzip", "", "small"},
+		{"already encoded", "gzip", "br", large},
+	} {
+		recorder := serveCompress(t, NewCompress(gzip.DefaultCompression), c.acceptEncoding, func(rw http.ResponseWriter, r *http.Request) {
+			if c.encoding != "" {
+				rw.Header().Set("Content-Encoding", c.encoding)
+			}
+			rw.Write([]byte(c.body))
+		})
+		if recorder.Header().Get("Content-Encoding") != c.encoding || recorder.Body.String() != c.body {
+			t.Errorf("%s: expected the response untouched, got Content-Encoding %q", c.name, recorder.Header().Get("Content-Encoding"))
+		}
+		expect(t, recorder.Header().Get("Vary"), "Accept-Encoding")
+	}
+}
+
+func TestCompressNoBody(t *testing.T) {
+	recorder := serveCompress(t, NewCompress(gzip.DefaultCompression), "gzip", func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusNoContent)
+	})
+	expect(t, recorder.Code, http.StatusNoContent)
+	expect(t, recorder.Header().Get("Content-Encoding"), "")
+	expect(t, recorder.Body.Len(), 0)
+}
+
+func TestCompressFlush(t *testing.T) {
+	recorder := serveCompress(t, NewCompress(gzip.DefaultCompression), "gzip", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte("data: 1\n\n"))
+		rw.(http.Flusher).Flush()
+		rw.Write([]byte(strings.Repeat("a", 2048)))
+	})
+	// Flushing early commits to an uncompressed stream.
+	expect(t, recorder.Header().Get("Content-Encoding"), "")
+	expect(t, recorder.Body.Len(), 9+2048)
+	expect(t, recorder.Flushed, true)
+}
+
+// hijackableRecorder is a ResponseRecorder that supports Hijack.
+type hijackableRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (h *hijackableRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h.hijacked = true
+	return nil, nil, nil
+}
+
+func TestCompressHijack(t *testing.T) {
+	hijackable := &hijackableRecorder{ResponseRecorder: httptest.NewRecorder()}
+
+	n := New()
+	n.Use(NewCompress(gzip.DefaultCompression))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		hijacker, ok := rw.(http.Hijacker)
+		expect(t, ok, true)
+		_, _, err := hijacker.Hijack()
+		expect(t, err, nil)
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("Accept-Encoding", "gzip")
+	n.ServeHTTP(hijackable, req)
+	expect(t, hijackable.hijacked, true)
+}
+
+func TestCompressLogger(t *testing.T) {
+	buff := bytes.NewBufferString("")
+	l := NewLogger()
+	l.Logger = log.New(buff, "", 0)
+
+	n := New()
+	n.Use(l)
+	n.Use(NewCompress(gzip.DefaultCompression))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusAccepted)
+		rw.Write([]byte(strings.Repeat("a", 4096)))
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("Accept-Encoding", "gzip")
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	if !strings.Contains(buff.String(), "Completed 202 Accepted") {
+		t.Errorf("Expected the status in the log, got %q", buff.String())
+	}
+}
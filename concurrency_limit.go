@@ -0,0 +1,60 @@
+package negroni
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ConcurrencyLimit is a middleware handler that caps how many requests are processed at the
+// same time. Requests over the limit wait for a slot for up to MaxWait and are then rejected
+// with 503 Service Unavailable, as are requests whose context is done while they wait. The
+// time spent waiting is recorded for QueueTime.
+type ConcurrencyLimit struct {
+	// Max is the number of requests processed concurrently. It must not be changed once the
+	// ConcurrencyLimit has served a request.
+	Max int
+	// MaxWait is how long a request waits for a slot. Zero rejects requests right away when
+	// all slots are taken.
+	MaxWait time.Duration
+
+	once  sync.Once
+	slots chan struct{}
+}
+
+// NewConcurrencyLimit returns a new instance of ConcurrencyLimit processing max requests at a
+// time and letting others wait up to maxWait
+func NewConcurrencyLimit(max int, maxWait time.Duration) *ConcurrencyLimit {
+	return &ConcurrencyLimit{Max: max, MaxWait: maxWait}
+}
+
+func (cl *ConcurrencyLimit) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	cl.once.Do(func() {
+		cl.slots = make(chan struct{}, cl.Max)
+	})
+
+	ctx := withQueueTime(r.Context())
+	start := time.Now()
+	select {
+	case cl.slots <- struct{}{}:
+	default:
+		timer := time.NewTimer(cl.MaxWait)
+		select {
+		case cl.slots <- struct{}{}:
+			timer.Stop()
+		case <-timer.C:
+			addQueueTime(ctx, time.Since(start))
+			http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		case <-ctx.Done():
+			timer.Stop()
+			addQueueTime(ctx, time.Since(start))
+			http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+		addQueueTime(ctx, time.Since(start))
+	}
+	defer func() { <-cl.slots }()
+
+	next(rw, r.WithContext(ctx))
+}
@@ -0,0 +1,104 @@
+package negroni
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConcurrencyLimitQueueTime(t *testing.T) {
+	buff := bytes.NewBufferString("")
+	l := NewLogger()
+	l.Logger = log.New(buff, "", 0)
+	l.LogQueueTime = true
+
+	entered := make(chan bool)
+	release := make(chan bool)
+	var queued time.Duration
+
+	n := New()
+	n.Use(l)
+	n.Use(NewConcurrencyLimit(1, time.Second))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			entered <- true
+			<-release
+			return
+		}
+		queued = QueueTime(r.Context())
+	})
+
+	done := make(chan bool)
+	go func() {
+		req, err := http.NewRequest("GET", "http://localhost:3000/slow", nil)
+		if err != nil {
+			t.Error(err)
+		}
+		n.ServeHTTP(httptest.NewRecorder(), req)
+		done <- true
+	}()
+	<-entered
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		release <- true
+	}()
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/fast", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	recorder := httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	<-done
+
+	expect(t, recorder.Code, http.StatusOK)
+	if queued < 10*time.Millisecond {
+		t.Errorf("Expected at least 10ms of queue time, got %v", queued)
+	}
+	if !strings.Contains(buff.String(), "(0 bytes) queued ") {
+		t.Errorf("Expected queue time in the log, got %q", buff.String())
+	}
+}
+
+func TestConcurrencyLimitMaxWait(t *testing.T) {
+	entered := make(chan bool)
+	release := make(chan bool)
+
+	n := New()
+	n.Use(NewConcurrencyLimit(1, 10*time.Millisecond))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			entered <- true
+			<-release
+		}
+	})
+
+	done := make(chan bool)
+	go func() {
+		req, err := http.NewRequest("GET", "http://localhost:3000/slow", nil)
+		if err != nil {
+			t.Error(err)
+		}
+		n.ServeHTTP(httptest.NewRecorder(), req)
+		done <- true
+	}()
+	<-entered
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/fast", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	recorder := httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusServiceUnavailable)
+
+	release <- true
+	<-done
+	recorder = httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusOK)
+}
@@ -0,0 +1,57 @@
+package negroni
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+// ContentLengthGuard is a middleware handler that verifies the request body matches its
+// declared Content-Length before handlers run, rejecting discrepancies that may indicate
+// request smuggling with 400 Bad Request. Bodies are read into memory to be checked, so bodies
+// declaring more than MaxBytes are rejected with 413 instead. Requests without a declared
+// length are passed through.
+type ContentLengthGuard struct {
+	// MaxBytes is the largest declared length that will be checked.
+	MaxBytes int64
+}
+
+// NewContentLengthGuard returns a new instance of ContentLengthGuard checking bodies up to 1MB
+func NewContentLengthGuard() *ContentLengthGuard {
+	return &ContentLengthGuard{MaxBytes: 1 << 20}
+}
+
+func (g *ContentLengthGuard) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	values := r.Header["Content-Length"]
+	if len(values) == 0 || r.Body == nil {
+		next(rw, r)
+		return
+	}
+
+	declared, err := strconv.ParseInt(values[0], 10, 64)
+	for _, v := range values[1:] {
+		if v != values[0] {
+			err = strconv.ErrSyntax
+		}
+	}
+	if err != nil || declared < 0 || (r.ContentLength >= 0 && r.ContentLength != declared) {
+		http.Error(rw, "invalid Content-Length", http.StatusBadRequest)
+		return
+	}
+	if declared > g.MaxBytes {
+		http.Error(rw, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, declared+1))
+	r.Body.Close()
+	if err != nil || int64(len(body)) != declared {
+		http.Error(rw, "body does not match Content-Length", http.StatusBadRequest)
+		return
+	}
+
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	next(rw, r)
+}
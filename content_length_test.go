@@ -0,0 +1,53 @@
+package negroni
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func serveContentLength(t *testing.T, body, declared string) (*httptest.ResponseRecorder, string) {
+	recorder := httptest.NewRecorder()
+	var got string
+
+	g := NewContentLengthGuard()
+	g.MaxBytes = 16
+
+	n := New()
+	n.Use(g)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		got = string(b)
+	})
+
+	req, err := http.NewRequest("POST", "http://localhost:3000/", strings.NewReader(body))
+	if err != nil {
+		t.Error(err)
+	}
+	req.ContentLength = -1
+	req.Header.Set("Content-Length", declared)
+
+	n.ServeHTTP(recorder, req)
+	return recorder, got
+}
+
+func TestContentLengthGuardMatching(t *testing.T) {
+	recorder, got := serveContentLength(t, "hello", "5")
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, got, "hello")
+}
+
+func TestContentLengthGuardMismatch(t *testing.T) {
+	for _, declared := range []string{"3", "10", "-1", "5x"} {
+		recorder, got := serveContentLength(t, "hello", declared)
+		expect(t, recorder.Code, http.StatusBadRequest)
+		expect(t, got, "")
+	}
+}
+
+func TestContentLengthGuardTooLarge(t *testing.T) {
+	recorder, _ := serveContentLength(t, strings.Repeat("a", 20), "20")
+	expect(t, recorder.Code, http.StatusRequestEntityTooLarge)
+}
@@ -0,0 +1,47 @@
+package negroni
+
+import (
+	"log"
+	"net/http"
+	"os"
+)
+
+// ContentTypeCheck is a middleware handler that makes sure responses declare a Content-Type,
+// so clients do not have to sniff it. If a handler did not set one by the time the response
+// is written, it either sets Default or, when Default is empty, logs a warning. Responses that
+// carry no body, such as 204 and 304, are left alone.
+type ContentTypeCheck struct {
+	// Default is the Content-Type set on responses that lack one. If empty, the response is
+	// only logged.
+	Default string
+	// Logger receives the warnings.
+	Logger *log.Logger
+}
+
+// NewContentTypeCheck returns a new instance of ContentTypeCheck that sets defaultType on
+// responses without a Content-Type, or only warns about them if defaultType is empty
+func NewContentTypeCheck(defaultType string) *ContentTypeCheck {
+	return &ContentTypeCheck{
+		Default: defaultType,
+		Logger:  log.New(os.Stdout, "[negroni] ", 0),
+	}
+}
+
+func (c *ContentTypeCheck) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	rw.(ResponseWriter).Before(func(rw ResponseWriter) {
+		status := rw.Status()
+		if !bodyAllowed(status) {
+			return
+		}
+		if _, ok := rw.Header()["Content-Type"]; ok {
+			return
+		}
+		if c.Default != "" {
+			rw.Header().Set("Content-Type", c.Default)
+			return
+		}
+		c.Logger.Printf("WARNING: %s %s responded %d without a Content-Type", r.Method, r.URL.Path, status)
+	})
+
+	next(rw, r)
+}
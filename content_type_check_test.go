@@ -0,0 +1,64 @@
+package negroni
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestContentTypeCheckDefault(t *testing.T) {
+	n := New()
+	n.Use(NewContentTypeCheck("application/json"))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/text" {
+			rw.Header().Set("Content-Type", "text/plain")
+		}
+		rw.Write([]byte("{}"))
+	})
+
+	for path, contentType := range map[string]string{
+		"/json": "application/json",
+		"/text": "text/plain",
+	} {
+		recorder := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "http://localhost:3000"+path, nil)
+		if err != nil {
+			t.Error(err)
+		}
+		n.ServeHTTP(recorder, req)
+		expect(t, recorder.Header().Get("Content-Type"), contentType)
+	}
+}
+
+func TestContentTypeCheckWarn(t *testing.T) {
+	buff := bytes.NewBufferString("")
+	check := NewContentTypeCheck("")
+	check.Logger = log.New(buff, "[negroni] ", 0)
+
+	n := New()
+	n.Use(check)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/empty" {
+			rw.WriteHeader(http.StatusNoContent)
+			return
+		}
+		rw.Write([]byte("hello"))
+	})
+
+	recorder := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "http://localhost:3000/empty", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(recorder, req)
+	expect(t, buff.String(), "")
+
+	req, err = http.NewRequest("GET", "http://localhost:3000/hello", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, buff.String(), "[negroni] WARNING: GET /hello responded 200 without a Content-Type\n")
+}
@@ -0,0 +1,38 @@
+package negroni
+
+import (
+	"context"
+	"time"
+)
+
+// IsClientGone reports whether the request context has been cancelled, which net/http does
+// when the client disconnects. Handlers can check it before computing an expensive response
+// that nobody will read.
+func IsClientGone(ctx context.Context) bool {
+	return ctx.Err() != nil
+}
+
+// rootedContext is a request context whose values fall back to a root context.
+type rootedContext struct {
+	context.Context
+	root context.Context
+}
+
+func (c rootedContext) Value(key interface{}) interface{} {
+	if v := c.Context.Value(key); v != nil {
+		return v
+	}
+	return c.root.Value(key)
+}
+
+// WithMaxDeadline returns a copy of ctx whose deadline is at most d from now. It only ever
+// tightens the deadline: if ctx already expires sooner, ctx is returned unchanged with a no-op
+// CancelFunc. Every deadline-setting middleware in this package goes through it, so when
+// several of them are stacked, e.g. GRPCTimeout, RouteTimeout and Timeout, the earliest
+// deadline governs regardless of their order.
+func WithMaxDeadline(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if deadline, ok := ctx.Deadline(); ok && !deadline.After(time.Now().Add(d)) {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, d)
+}
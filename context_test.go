@@ -0,0 +1,76 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIsClientGone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	expect(t, IsClientGone(ctx), false)
+	cancel()
+	expect(t, IsClientGone(ctx), true)
+}
+
+func TestIsClientGoneThroughChain(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	gone := false
+
+	n := New()
+	n.Use(NewHeaderDedupe())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		cancel()
+		gone = IsClientGone(r.Context())
+	})
+
+	req, err := http.NewRequestWithContext(ctx, "GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, gone, true)
+}
+
+func TestWithMaxDeadlineOnlyTightens(t *testing.T) {
+	ctx, cancel := WithMaxDeadline(context.Background(), time.Minute)
+	defer cancel()
+	first, _ := ctx.Deadline()
+
+	looser, cancel := WithMaxDeadline(ctx, time.Hour)
+	defer cancel()
+	deadline, _ := looser.Deadline()
+	expect(t, deadline, first)
+
+	tighter, cancel := WithMaxDeadline(ctx, time.Second)
+	defer cancel()
+	deadline, _ = tighter.Deadline()
+	expect(t, deadline.Before(first), true)
+}
+
+func TestStackedDeadlinesTightestWins(t *testing.T) {
+	for _, order := range [][]Handler{
+		{NewGRPCTimeout(), NewRouteTimeout(time.Hour), NewTimeout(time.Minute)},
+		{NewTimeout(time.Minute), NewRouteTimeout(time.Hour), NewGRPCTimeout()},
+	} {
+		var remaining time.Duration
+
+		n := New(order...)
+		n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			deadline, _ := r.Context().Deadline()
+			remaining = time.Until(deadline)
+		})
+
+		req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+		if err != nil {
+			t.Error(err)
+		}
+		req.Header.Set("grpc-timeout", "2S")
+		n.ServeHTTP(httptest.NewRecorder(), req)
+		if remaining <= 0 || remaining > 2*time.Second {
+			t.Errorf("Expected the 2s grpc-timeout to govern, got %v", remaining)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package negroni
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSOptions configures a CORS middleware.
+type CORSOptions struct {
+	// AllowedOrigins lists the origins allowed to make cross-origin requests, e.g.
+	// "https://app.example.com", or "*" for any origin.
+	AllowedOrigins []string
+	// AllowedMethods lists the methods allowed in cross-origin requests. It defaults to GET,
+	// HEAD and POST.
+	AllowedMethods []string
+	// AllowedHeaders lists the request headers allowed in cross-origin requests, or "*" to
+	// allow whichever headers the preflight request asks for.
+	AllowedHeaders []string
+	// AllowCredentials lets cross-origin requests include cookies and HTTP authentication.
+	// The allowed origin is then echoed back instead of "*", as browsers require.
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache the result of a preflight request. Zero leaves
+	// it to the browser.
+	MaxAge time.Duration
+}
+
+// CORS is a middleware handler implementing Cross-Origin Resource Sharing. Preflight requests,
+// OPTIONS requests carrying Access-Control-Request-Method, are answered directly with 204 No
+// Content without invoking the rest of the chain; other requests from an allowed origin get
+// Access-Control-Allow-* headers and are passed on. Requests from origins that are not allowed
+// get no CORS headers, so browsers block them.
+type CORS struct {
+	CORSOptions
+}
+
+// NewCORS returns a new instance of CORS configured with opts
+func NewCORS(opts CORSOptions) *CORS {
+	return &CORS{CORSOptions: opts}
+}
+
+func (c *CORS) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	origin := r.Header.Get("Origin")
+	preflight := r.Method == "OPTIONS" && r.Header.Get("Access-Control-Request-Method") != ""
+	if origin == "" {
+		next(rw, r)
+		return
+	}
+
+	h := rw.Header()
+	allowed := c.allowedOrigin(origin)
+	if allowed != "*" {
+		h.Add("Vary", "Origin")
+	}
+	if allowed != "" {
+		h.Set("Access-Control-Allow-Origin", allowed)
+		if c.AllowCredentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+	}
+
+	if !preflight {
+		next(rw, r)
+		return
+	}
+
+	h.Add("Vary", "Access-Control-Request-Method")
+	h.Add("Vary", "Access-Control-Request-Headers")
+	if allowed != "" {
+		methods := c.AllowedMethods
+		if len(methods) == 0 {
+			methods = []string{"GET", "HEAD", "POST"}
+		}
+		h.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+		if containsString(c.AllowedHeaders, "*") {
+			if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+				h.Set("Access-Control-Allow-Headers", requested)
+			}
+		} else if len(c.AllowedHeaders) > 0 {
+			h.Set("Access-Control-Allow-Headers", strings.Join(c.AllowedHeaders, ", "))
+		}
+		if c.MaxAge > 0 {
+			h.Set("Access-Control-Max-Age", strconv.Itoa(int(c.MaxAge/time.Second)))
+		}
+	}
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// allowedOrigin returns the value of Access-Control-Allow-Origin for origin, or "" if origin
+// is not allowed.
+func (c *CORS) allowedOrigin(origin string) string {
+	for _, o := range c.AllowedOrigins {
+		if o == "*" {
+			if c.AllowCredentials {
+				return origin
+			}
+			return "*"
+		}
+		if strings.EqualFold(o, origin) {
+			return origin
+		}
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func serveCORS(t *testing.T, opts CORSOptions, method, origin string, header http.Header) (*httptest.ResponseRecorder, bool) {
+	recorder := httptest.NewRecorder()
+	called := false
+
+	n := New()
+	n.Use(NewCORS(opts))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		called = true
+		rw.Write([]byte("ok"))
+	})
+
+	req, err := http.NewRequest(method, "http://localhost:3000/api", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	n.ServeHTTP(recorder, req)
+	return recorder, called
+}
+
+func TestCORSPreflight(t *testing.T) {
+	opts := CORSOptions{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{"GET", "PUT", "DELETE"},
+		AllowedHeaders: []string{"Content-Type", "Authorization"},
+		MaxAge:         10 * time.Minute,
+	}
+	recorder, called := serveCORS(t, opts, "OPTIONS", "https://app.example.com", http.Header{
+		"Access-Control-Request-Method": {"PUT"},
+	})
+	expect(t, called, false)
+	expect(t, recorder.Code, http.StatusNoContent)
+	expect(t, recorder.Header().Get("Access-Control-Allow-Origin"), "https://app.example.com")
+	expect(t, recorder.Header().Get("Access-Control-Allow-Methods"), "GET, PUT, DELETE")
+	expect(t, recorder.Header().Get("Access-Control-Allow-Headers"), "Content-Type, Authorization")
+	expect(t, recorder.Header().Get("Access-Control-Max-Age"), "600")
+	expect(t, recorder.Header().Get("Access-Control-Allow-Credentials"), "")
+}
+
+func TestCORSPreflightDisallowedOrigin(t *testing.T) {
+	opts := CORSOptions{AllowedOrigins: []string{"https://app.example.com"}}
+	recorder, called := serveCORS(t, opts, "OPTIONS", "https://evil.example.com", http.Header{
+		"Access-Control-Request-Method": {"GET"},
+	})
+	expect(t, called, false)
+	expect(t, recorder.Code, http.StatusNoContent)
+	expect(t, recorder.Header().Get("Access-Control-Allow-Origin"), "")
+	expect(t, recorder.Header().Get("Access-Control-Allow-Methods"), "")
+}
+
+func TestCORSActualRequest(t *testing.T) {
+	opts := CORSOptions{AllowedOrigins: []string{"*"}}
+	recorder, called := serveCORS(t, opts, "GET", "https://app.example.com", nil)
+	expect(t, called, true)
+	expect(t, recorder.Body.String(), "ok")
+	expect(t, recorder.Header().Get("Access-Control-Allow-Origin"), "*")
+	expect(t, recorder.Header().Get("Vary"), "")
+
+	// Plain OPTIONS requests are not preflights.
+	_, called = serveCORS(t, opts, "OPTIONS", "https://app.example.com", nil)
+	expect(t, called, true)
+
+	// Requests without an Origin are not cross-origin.
+	recorder, called = serveCORS(t, opts, "GET", "", nil)
+	expect(t, called, true)
+	expect(t, recorder.Header().Get("Access-Control-Allow-Origin"), "")
+}
+
+func TestCORSCredentials(t *testing.T) {
+	opts := CORSOptions{AllowedOrigins: []string{"*"}, AllowCredentials: true, AllowedHeaders: []string{"*"}}
+	recorder, _ := serveCORS(t, opts, "OPTIONS", "https://app.example.com", http.Header{
+		"Access-Control-Request-Method":  {"POST"},
+		"Access-Control-Request-Headers": {"X-Token"},
+	})
+	expect(t, recorder.Header().Get("Access-Control-Allow-Origin"), "https://app.example.com")
+	expect(t, recorder.Header().Get("Access-Control-Allow-Credentials"), "true")
+	expect(t, recorder.Header().Get("Access-Control-Allow-Methods"), "GET, HEAD, POST")
+	expect(t, recorder.Header().Get("Access-Control-Allow-Headers"), "X-Token")
+	expect(t, strings.Join(recorder.Header()["Vary"], ", "), "Origin, Access-Control-Request-Method, Access-Control-Request-Headers")
+}
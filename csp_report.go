@@ -0,0 +1,73 @@
+package negroni
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// CSPReport is a Content Security Policy violation report as sent by browsers.
+type CSPReport struct {
+	DocumentURI        string `json:"document-uri"`
+	Referrer           string `json:"referrer"`
+	BlockedURI         string `json:"blocked-uri"`
+	ViolatedDirective  string `json:"violated-directive"`
+	EffectiveDirective string `json:"effective-directive"`
+	OriginalPolicy     string `json:"original-policy"`
+	Disposition        string `json:"disposition"`
+	SourceFile         string `json:"source-file"`
+	LineNumber         int    `json:"line-number"`
+	StatusCode         int    `json:"status-code"`
+}
+
+// CSPReportOnly is a middleware handler that sets a Content-Security-Policy-Report-Only header,
+// so a policy can be rolled out without breaking pages, and receives the violation reports
+// browsers post to ReportPath, handing them to Sink.
+type CSPReportOnly struct {
+	// Policy is the policy to report on, e.g. "default-src 'self'".
+	Policy string
+	// ReportPath is the path reports are posted to. It is added to the policy as report-uri.
+	// When empty no report-uri is added and no reports are collected.
+	ReportPath string
+	// Sink receives parsed reports.
+	Sink func(r *http.Request, report CSPReport)
+}
+
+// NewCSPReportOnly returns a new instance of CSPReportOnly for policy, collecting reports
+// posted to reportPath into sink
+func NewCSPReportOnly(policy, reportPath string, sink func(*http.Request, CSPReport)) *CSPReportOnly {
+	return &CSPReportOnly{
+		Policy:     policy,
+		ReportPath: reportPath,
+		Sink:       sink,
+	}
+}
+
+func (c *CSPReportOnly) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if c.ReportPath != "" && r.URL.Path == c.ReportPath && r.Method == "POST" {
+		c.collect(rw, r)
+		return
+	}
+
+	policy := c.Policy
+	if c.ReportPath != "" {
+		policy += "; report-uri " + c.ReportPath
+	}
+	rw.Header().Set("Content-Security-Policy-Report-Only", policy)
+
+	next(rw, r)
+}
+
+func (c *CSPReportOnly) collect(rw http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Report CSPReport `json:"csp-report"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, 64<<10)).Decode(&body); err != nil {
+		http.Error(rw, "invalid CSP report", http.StatusBadRequest)
+		return
+	}
+	if c.Sink != nil {
+		c.Sink(r, body.Report)
+	}
+	rw.WriteHeader(http.StatusNoContent)
+}
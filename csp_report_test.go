@@ -0,0 +1,64 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCSPReportOnlyHeader(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	n.Use(NewCSPReportOnly("default-src 'self'", "/csp-report", nil))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte("page"))
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Header().Get("Content-Security-Policy-Report-Only"), "default-src 'self'; report-uri /csp-report")
+	expect(t, recorder.Body.String(), "page")
+}
+
+func TestCSPReportOnlyCollectsReports(t *testing.T) {
+	var reports []CSPReport
+	called := false
+
+	n := New()
+	n.Use(NewCSPReportOnly("default-src 'self'", "/csp-report", func(r *http.Request, report CSPReport) {
+		reports = append(reports, report)
+	}))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	body := `{"csp-report": {"document-uri": "https://example.com/page", "blocked-uri": "https://evil.com/x.js", "violated-directive": "script-src-elem", "line-number": 12}}`
+	req, err := http.NewRequest("POST", "http://localhost:3000/csp-report", strings.NewReader(body))
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("Content-Type", "application/csp-report")
+
+	recorder := httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusNoContent)
+	expect(t, called, false)
+	expect(t, len(reports), 1)
+	expect(t, reports[0].DocumentURI, "https://example.com/page")
+	expect(t, reports[0].BlockedURI, "https://evil.com/x.js")
+	expect(t, reports[0].ViolatedDirective, "script-src-elem")
+	expect(t, reports[0].LineNumber, 12)
+
+	req, err = http.NewRequest("POST", "http://localhost:3000/csp-report", strings.NewReader("nope"))
+	if err != nil {
+		t.Error(err)
+	}
+	recorder = httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusBadRequest)
+}
@@ -0,0 +1,85 @@
+package negroni
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrBodyTooLarge is returned when reading a decompressed request body beyond
+// Decompress.MaxBytes.
+var ErrBodyTooLarge = errors.New("negroni: decompressed request body too large")
+
+// Decompress is a middleware handler that transparently decompresses gzip encoded request
+// bodies. To guard against decompression bombs, reading more than MaxBytes decompressed bytes
+// fails with ErrBodyTooLarge; if the handler then returns without writing a response, a 413 is
+// sent for it.
+type Decompress struct {
+	// MaxBytes is the maximum decompressed body size. Zero means no limit.
+	MaxBytes int64
+}
+
+// NewDecompress returns a new instance of Decompress limiting bodies to 10MB
+func NewDecompress() *Decompress {
+	return &Decompress{MaxBytes: 10 << 20}
+}
+
+func (d *Decompress) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if r.Body == nil || !strings.EqualFold(r.Header.Get("Content-Encoding"), "gzip") {
+		next(rw, r)
+		return
+	}
+
+	zr, err := gzip.NewReader(r.Body)
+	if err != nil {
+		http.Error(rw, "invalid gzip body", http.StatusBadRequest)
+		return
+	}
+
+	// The decoded body and headers go on a copy so the caller's request is left as it was.
+	body := &limitedBody{ReadCloser: r.Body, r: zr, remaining: d.MaxBytes, limited: d.MaxBytes > 0}
+	r2 := r.Clone(r.Context())
+	r2.Body = body
+	r2.ContentLength = -1
+	r2.Header.Del("Content-Encoding")
+	r2.Header.Del("Content-Length")
+
+	next(rw, r2)
+
+	if body.exceeded && !rw.(ResponseWriter).Written() {
+		http.Error(rw, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+	}
+}
+
+// limitedBody reads from r and closes the original body, failing once more than remaining
+// bytes have been read.
+type limitedBody struct {
+	io.ReadCloser
+	r         io.Reader
+	remaining int64
+	limited   bool
+	exceeded  bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if !b.limited {
+		return b.r.Read(p)
+	}
+	if b.remaining < 0 {
+		b.exceeded = true
+		return 0, ErrBodyTooLarge
+	}
+	// Read one byte past the limit so that a body of exactly the limit still succeeds.
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.r.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		b.exceeded = true
+		return n + int(b.remaining), ErrBodyTooLarge
+	}
+	return n, err
+}
@@ -0,0 +1,106 @@
+package negroni
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func gzipBody(t *testing.T, s string) *bytes.Buffer {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		t.Error(err)
+	}
+	zw.Close()
+	return &buf
+}
+
+func serveDecompress(t *testing.T, d *Decompress, body *bytes.Buffer) (*httptest.ResponseRecorder, string, error) {
+	recorder := httptest.NewRecorder()
+	var got string
+	var readErr error
+
+	n := New()
+	n.Use(d)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		got, readErr = string(b), err
+		if err == nil {
+			rw.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	req, err := http.NewRequest("POST", "http://localhost:3000/", body)
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("Content-Encoding", "gzip")
+
+	n.ServeHTTP(recorder, req)
+	return recorder, got, readErr
+}
+
+func TestDecompress(t *testing.T) {
+	recorder, got, err := serveDecompress(t, NewDecompress(), gzipBody(t, "hello world"))
+	expect(t, err, nil)
+	expect(t, got, "hello world")
+	expect(t, recorder.Code, http.StatusNoContent)
+}
+
+func TestDecompressExactLimit(t *testing.T) {
+	d := NewDecompress()
+	d.MaxBytes = 11
+
+	recorder, got, err := serveDecompress(t, d, gzipBody(t, "hello world"))
+	expect(t, err, nil)
+	expect(t, got, "hello world")
+	expect(t, recorder.Code, http.StatusNoContent)
+}
+
+func TestDecompressBomb(t *testing.T) {
+	d := NewDecompress()
+	d.MaxBytes = 1024
+
+	body := gzipBody(t, strings.Repeat("a", 1<<20))
+	if body.Len() > 4096 {
+		t.Errorf("Expected a small compressed payload, got %d bytes", body.Len())
+	}
+
+	recorder, got, err := serveDecompress(t, d, body)
+	expect(t, err, ErrBodyTooLarge)
+	expect(t, len(got), 1024)
+	expect(t, recorder.Code, http.StatusRequestEntityTooLarge)
+}
+
+func TestDecompressInvalid(t *testing.T) {
+	recorder, _, _ := serveDecompress(t, NewDecompress(), bytes.NewBufferString("not gzip"))
+	expect(t, recorder.Code, http.StatusBadRequest)
+}
+
+func TestDecompressLeavesRequest(t *testing.T) {
+	var encoding string
+
+	n := New()
+	n.Use(NewDecompress())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		encoding = r.Header.Get("Content-Encoding")
+	})
+
+	body := ioutil.NopCloser(gzipBody(t, "hello"))
+	req, err := http.NewRequest("POST", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.Body = body
+	req.Header.Set("Content-Encoding", "gzip")
+
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, encoding, "")
+	expect(t, req.Header.Get("Content-Encoding"), "gzip")
+	expect(t, req.Body, body)
+}
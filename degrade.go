@@ -0,0 +1,121 @@
+package negroni
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+type degradeKey struct{}
+
+type degradation struct {
+	d        *Degrade
+	mu       sync.Mutex
+	features []string
+}
+
+// Optional runs fn, a piece of non-critical work such as enrichment or recommendations, under
+// the soft timeout the Degrade middleware configured for feature. If fn fails or the soft
+// deadline passes first, the feature is recorded as degraded and Optional returns false so the
+// handler can carry on without it; fn keeps running in the background until it honors the
+// cancellation of its context. A panic in fn counts as a failure too. Without a Degrade in
+// the stack, fn runs with no soft timeout.
+func Optional(ctx context.Context, feature string, fn func(ctx context.Context) error) bool {
+	deg, _ := ctx.Value(degradeKey{}).(*degradation)
+	if deg == nil {
+		return runOptional(ctx, fn) == nil
+	}
+
+	ctx, cancel := WithMaxDeadline(ctx, deg.d.timeout(feature))
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- runOptional(ctx, fn)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err == nil {
+		return true
+	}
+
+	deg.mu.Lock()
+	deg.features = append(deg.features, feature)
+	deg.mu.Unlock()
+	AddLogField(ctx, "degraded", feature)
+	return false
+}
+
+// runOptional calls fn, turning a panic into an error. fn may run on its own goroutine, where
+// Recovery could not catch the panic and it would take the whole process down.
+func runOptional(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return fn(ctx)
+}
+
+// Degraded returns the optional features that were skipped for the request ctx belongs to.
+func Degraded(ctx context.Context) []string {
+	deg, _ := ctx.Value(degradeKey{}).(*degradation)
+	if deg == nil {
+		return nil
+	}
+	deg.mu.Lock()
+	defer deg.mu.Unlock()
+	return append([]string(nil), deg.features...)
+}
+
+// Degrade is a middleware handler that lets handlers run optional work with Optional, so
+// a slow or failing optional feature degrades the response instead of failing the request.
+// Each feature gets a short soft timeout. Degraded features are added to the structured log
+// entry, and listed in Header if the response has not been written when they degrade.
+type Degrade struct {
+	// Timeouts maps a feature to its soft timeout.
+	Timeouts map[string]time.Duration
+	// Default is the soft timeout of features missing from Timeouts.
+	Default time.Duration
+	// Header lists the degraded features on the response. Empty disables it.
+	Header string
+}
+
+// NewDegrade returns a new instance of Degrade giving optional features 100ms by default
+func NewDegrade() *Degrade {
+	return &Degrade{
+		Timeouts: make(map[string]time.Duration),
+		Default:  100 * time.Millisecond,
+		Header:   "X-Degraded",
+	}
+}
+
+func (d *Degrade) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	deg := &degradation{d: d}
+	if d.Header != "" {
+		rw.(ResponseWriter).Before(func(rw ResponseWriter) {
+			deg.mu.Lock()
+			defer deg.mu.Unlock()
+			if len(deg.features) > 0 {
+				rw.Header().Set(d.Header, strings.Join(deg.features, ", "))
+			}
+		})
+	}
+
+	next(rw, r.WithContext(context.WithValue(r.Context(), degradeKey{}, deg)))
+}
+
+func (d *Degrade) timeout(feature string) time.Duration {
+	if t, ok := d.Timeouts[feature]; ok {
+		return t
+	}
+	return d.Default
+}
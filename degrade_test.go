@@ -0,0 +1,92 @@
+package negroni
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDegradeOptionalTimeout(t *testing.T) {
+	buff := bytes.NewBufferString("")
+	l := NewLogger()
+	l.Logger = log.New(buff, "", 0)
+	l.Structured = true
+
+	d := NewDegrade()
+	d.Timeouts["recommendations"] = 10 * time.Millisecond
+
+	n := New(l, d)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body := "product"
+		if Optional(r.Context(), "recommendations", func(ctx context.Context) error {
+			select {
+			case <-time.After(time.Second):
+				body += " +recommendations"
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}) {
+			t.Error("expected recommendations to degrade")
+		}
+		if !Optional(r.Context(), "price", func(ctx context.Context) error {
+			body += " +price"
+			return nil
+		}) {
+			t.Error("expected price to succeed")
+		}
+		Optional(r.Context(), "reviews", func(ctx context.Context) error {
+			return errors.New("reviews unavailable")
+		})
+		expect(t, strings.Join(Degraded(r.Context()), ","), "recommendations,reviews")
+		rw.Write([]byte(body))
+	})
+
+	recorder := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "http://localhost:3000/products/1", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, recorder.Body.String(), "product +price")
+	expect(t, recorder.Header().Get("X-Degraded"), "recommendations, reviews")
+	expect(t, strings.Contains(buff.String(), "degraded=recommendations degraded=reviews"), true)
+}
+
+func TestOptionalWithoutDegrade(t *testing.T) {
+	expect(t, Optional(context.Background(), "price", func(ctx context.Context) error { return nil }), true)
+	expect(t, len(Degraded(context.Background())), 0)
+}
+
+func TestDegradeOptionalPanic(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	n := New(NewDegrade())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		ok := Optional(r.Context(), "reviews", func(ctx context.Context) error {
+			panic("reviews exploded")
+		})
+		expect(t, ok, false)
+		rw.Write([]byte("product"))
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/products/1", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, recorder.Body.String(), "product")
+	expect(t, recorder.Header().Get("X-Degraded"), "reviews")
+
+	expect(t, Optional(context.Background(), "reviews", func(ctx context.Context) error {
+		panic("reviews exploded")
+	}), false)
+}
@@ -0,0 +1,81 @@
+package negroni
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// HandlerDescriptor summarizes a handler for Negroni.DescribeJSON.
+type HandlerDescriptor struct {
+	// Name identifies the handler. It defaults to the handler's type.
+	Name string `json:"name"`
+	// Config holds the configuration the handler chooses to expose. Values of keys that
+	// look sensitive, such as passwords, secrets and tokens, are redacted.
+	Config map[string]interface{} `json:"config,omitempty"`
+}
+
+// Describer is implemented by handlers that expose a summary of their configuration to
+// Negroni.DescribeJSON.
+type Describer interface {
+	Describe() HandlerDescriptor
+}
+
+// sensitiveConfigKeys are the substrings that mark a Config key as sensitive.
+var sensitiveConfigKeys = []string{"password", "secret", "token", "key", "credential", "auth"}
+
+// DescribeJSON returns the middleware stack as JSON, in order, for an admin or debug endpoint
+// that lets operators verify the deployed stack, e.g.
+//
+//	{"handlers":[{"index":0,"name":"*negroni.Recovery","config":{"print_stack":true}}]}
+//
+// Handlers that do not implement Describer are listed by type only.
+func (n *Negroni) DescribeJSON() ([]byte, error) {
+	type handler struct {
+		Index int `json:"index"`
+		HandlerDescriptor
+	}
+	stack := struct {
+		Handlers []handler `json:"handlers"`
+	}{Handlers: []handler{}}
+
+	for i, h := range n.handlers {
+		stack.Handlers = append(stack.Handlers, handler{i, describe(h)})
+	}
+	return json.Marshal(stack)
+}
+
+func describe(h Handler) HandlerDescriptor {
+	var d HandlerDescriptor
+	if w, ok := h.(wrapped); ok {
+		if desc, ok := w.handler.(Describer); ok {
+			d = desc.Describe()
+		}
+	} else if desc, ok := h.(Describer); ok {
+		d = desc.Describe()
+	}
+	if d.Name == "" {
+		d.Name = handlerName(h)
+	}
+
+	if d.Config != nil {
+		config := make(map[string]interface{}, len(d.Config))
+		for k, v := range d.Config {
+			if sensitiveConfigKey(k) {
+				v = "REDACTED"
+			}
+			config[k] = v
+		}
+		d.Config = config
+	}
+	return d
+}
+
+func sensitiveConfigKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitiveConfigKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package negroni
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+type describedAuth struct{}
+
+func (describedAuth) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	next(rw, r)
+}
+
+func (describedAuth) Describe() HandlerDescriptor {
+	return HandlerDescriptor{
+		Name: "auth",
+		Config: map[string]interface{}{
+			"realm":      "admin",
+			"api_key":    "s3cr3t",
+			"jwt_secret": "hunter2",
+		},
+	}
+}
+
+func TestDescribeJSON(t *testing.T) {
+	n := New(NewRecovery(), NewTimeout(time.Second), describedAuth{})
+	n.UseHandler(http.NotFoundHandler())
+
+	b, err := n.DescribeJSON()
+	expect(t, err, nil)
+	expect(t, string(b), `{"handlers":[`+
+		`{"index":0,"name":"*negroni.Recovery","config":{"print_stack":true,"production_mode":false,"stack_all":false,"stack_size":8192}},`+
+		`{"index":1,"name":"*negroni.Timeout","config":{"duration":"1s"}},`+
+		`{"index":2,"name":"auth","config":{"api_key":"REDACTED","jwt_secret":"REDACTED","realm":"admin"}},`+
+		`{"index":3,"name":"http.HandlerFunc"}]}`)
+}
+
+func TestDescribeJSONEmpty(t *testing.T) {
+	b, err := New().DescribeJSON()
+	expect(t, err, nil)
+	expect(t, string(b), `{"handlers":[]}`)
+}
@@ -0,0 +1,42 @@
+package negroni
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// Drain is a middleware handler that asks clients to reconnect elsewhere while the server is
+// overloaded or shutting down. While draining, every response carries a Connection: close
+// header, which lets load balancers rebalance connections.
+type Drain struct {
+	draining int32
+}
+
+// NewDrain returns a new instance of Drain that is not draining
+func NewDrain() *Drain {
+	return &Drain{}
+}
+
+// SetDraining turns draining on or off. It is safe to call from any goroutine.
+func (d *Drain) SetDraining(draining bool) {
+	var v int32
+	if draining {
+		v = 1
+	}
+	atomic.StoreInt32(&d.draining, v)
+}
+
+// Draining reports whether responses are currently marked with Connection: close.
+func (d *Drain) Draining() bool {
+	return atomic.LoadInt32(&d.draining) == 1
+}
+
+func (d *Drain) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	rw.(ResponseWriter).Before(func(rw ResponseWriter) {
+		if d.Draining() {
+			rw.Header().Set("Connection", "close")
+		}
+	})
+
+	next(rw, r)
+}
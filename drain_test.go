@@ -0,0 +1,32 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDrain(t *testing.T) {
+	d := NewDrain()
+
+	n := New()
+	n.Use(d)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte("ok"))
+	})
+
+	recorder := httptest.NewRecorder()
+	n.ServeHTTP(recorder, (*http.Request)(nil))
+	expect(t, recorder.Header().Get("Connection"), "")
+
+	d.SetDraining(true)
+	expect(t, d.Draining(), true)
+	recorder = httptest.NewRecorder()
+	n.ServeHTTP(recorder, (*http.Request)(nil))
+	expect(t, recorder.Header().Get("Connection"), "close")
+
+	d.SetDraining(false)
+	recorder = httptest.NewRecorder()
+	n.ServeHTTP(recorder, (*http.Request)(nil))
+	expect(t, recorder.Header().Get("Connection"), "")
+}
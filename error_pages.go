@@ -0,0 +1,51 @@
+package negroni
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+// ErrorPages is a middleware handler that serves pre-rendered error pages, such as 404.html
+// or 500.html, from a directory when a handler responds with an error status but no body.
+// Error responses are buffered to detect the empty body; all others are streamed as usual.
+type ErrorPages struct {
+	// Dir is the directory containing the error pages, named after their status code.
+	Dir http.FileSystem
+}
+
+// NewErrorPages returns a new instance of ErrorPages serving pages from directory
+func NewErrorPages(directory http.FileSystem) *ErrorPages {
+	return &ErrorPages{Dir: directory}
+}
+
+func (e *ErrorPages) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	buf := newResponseBuffer(rw.(ResponseWriter), func(status int, h http.Header) bool {
+		return status >= http.StatusBadRequest
+	})
+
+	next(buf, r)
+
+	if buf.buffered() && buf.Status() >= http.StatusBadRequest && buf.body.Len() == 0 {
+		if page, ok := e.page(buf.Status()); ok {
+			buf.header.Set("Content-Type", "text/html; charset=utf-8")
+			buf.header.Set("Content-Length", strconv.Itoa(len(page)))
+			buf.body.Write(page)
+		}
+	}
+	buf.flush()
+}
+
+func (e *ErrorPages) page(status int) ([]byte, bool) {
+	f, err := e.Dir.Open("/" + strconv.Itoa(status) + ".html")
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+
+	page, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, false
+	}
+	return page, true
+}
@@ -0,0 +1,62 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func serveErrorPages(status int, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	n.Use(NewErrorPages(http.Dir("testdata/errorpages")))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(status)
+		rw.Write([]byte(body))
+	})
+
+	n.ServeHTTP(recorder, (*http.Request)(nil))
+	return recorder
+}
+
+func TestErrorPagesCustomPage(t *testing.T) {
+	recorder := serveErrorPages(http.StatusNotFound, "")
+	expect(t, recorder.Code, http.StatusNotFound)
+	expect(t, recorder.Body.String(), "<h1>Not Found</h1>\n")
+	expect(t, recorder.Header().Get("Content-Type"), "text/html; charset=utf-8")
+}
+
+func TestErrorPagesNoCustomPage(t *testing.T) {
+	recorder := serveErrorPages(http.StatusInternalServerError, "")
+	expect(t, recorder.Code, http.StatusInternalServerError)
+	expect(t, recorder.Body.String(), "")
+}
+
+func TestErrorPagesHandlerBody(t *testing.T) {
+	recorder := serveErrorPages(http.StatusNotFound, "no such user")
+	expect(t, recorder.Code, http.StatusNotFound)
+	expect(t, recorder.Body.String(), "no such user")
+}
+
+func TestErrorPagesSuccess(t *testing.T) {
+	recorder := serveErrorPages(http.StatusOK, "hello")
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, recorder.Body.String(), "hello")
+}
+
+func TestErrorPagesHeadersOnly(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	n.Use(NewErrorPages(http.Dir("testdata/errorpages")))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Location", "/login")
+		rw.Header().Set("Set-Cookie", "session=abc")
+	})
+
+	n.ServeHTTP(recorder, (*http.Request)(nil))
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, recorder.Header().Get("Location"), "/login")
+	expect(t, recorder.Header().Get("Set-Cookie"), "session=abc")
+}
@@ -0,0 +1,66 @@
+package negroni
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var grpcTimeoutUnits = map[byte]time.Duration{
+	'H': time.Hour,
+	'M': time.Minute,
+	'S': time.Second,
+	'm': time.Millisecond,
+	'u': time.Microsecond,
+	'n': time.Nanosecond,
+}
+
+// ParseGRPCTimeout parses a grpc-timeout header value such as "100m" or "5S": at most eight
+// digits followed by one of the units H, M, S, m, u or n.
+func ParseGRPCTimeout(s string) (time.Duration, error) {
+	if len(s) < 2 || len(s) > 9 {
+		return 0, fmt.Errorf("negroni: invalid grpc-timeout %q", s)
+	}
+	unit, ok := grpcTimeoutUnits[s[len(s)-1]]
+	if !ok {
+		return 0, fmt.Errorf("negroni: invalid grpc-timeout unit in %q", s)
+	}
+	v, err := strconv.ParseUint(s[:len(s)-1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("negroni: invalid grpc-timeout %q", s)
+	}
+	if d := time.Duration(v); d > (1<<63-1)/unit {
+		return 1<<63 - 1, nil
+	}
+	return time.Duration(v) * unit, nil
+}
+
+// GRPCTimeout is a middleware handler that reads the grpc-timeout request header, as sent by
+// gRPC clients through HTTP gateways, and applies it as a deadline on the request context.
+// Requests with a malformed header are rejected with 400.
+type GRPCTimeout struct{}
+
+// NewGRPCTimeout returns a new instance of GRPCTimeout
+func NewGRPCTimeout() *GRPCTimeout {
+	return &GRPCTimeout{}
+}
+
+func (g *GRPCTimeout) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	header := r.Header.Get("Grpc-Timeout")
+	if header == "" {
+		next(rw, r)
+		return
+	}
+
+	d, err := ParseGRPCTimeout(header)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := WithMaxDeadline(r.Context(), d)
+	defer cancel()
+
+	next(rw, r.WithContext(ctx))
+}
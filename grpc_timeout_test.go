@@ -0,0 +1,70 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseGRPCTimeout(t *testing.T) {
+	cases := map[string]time.Duration{
+		"1H":        time.Hour,
+		"2M":        2 * time.Minute,
+		"5S":        5 * time.Second,
+		"100m":      100 * time.Millisecond,
+		"250u":      250 * time.Microsecond,
+		"99999999n": 99999999 * time.Nanosecond,
+	}
+	for s, want := range cases {
+		d, err := ParseGRPCTimeout(s)
+		expect(t, err, nil)
+		expect(t, d, want)
+	}
+
+	for _, s := range []string{"", "5", "S", "5s", "-5S", "123456789S"} {
+		_, err := ParseGRPCTimeout(s)
+		refute(t, err, nil)
+	}
+}
+
+func TestGRPCTimeout(t *testing.T) {
+	var remaining time.Duration
+	hasDeadline := false
+
+	n := New()
+	n.Use(NewGRPCTimeout())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var deadline time.Time
+		deadline, hasDeadline = r.Context().Deadline()
+		remaining = time.Until(deadline)
+	})
+
+	req, err := http.NewRequest("POST", "http://localhost:3000/pkg.Service/Method", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("grpc-timeout", "100m")
+
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, hasDeadline, true)
+	if remaining > 100*time.Millisecond {
+		t.Errorf("Expected deadline of at most 100ms, got %v", remaining)
+	}
+}
+
+func TestGRPCTimeoutMalformed(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	n.Use(NewGRPCTimeout())
+
+	req, err := http.NewRequest("POST", "http://localhost:3000/pkg.Service/Method", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("grpc-timeout", "soon")
+
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusBadRequest)
+}
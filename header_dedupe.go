@@ -0,0 +1,55 @@
+package negroni
+
+import (
+	"net/http"
+	"strings"
+)
+
+// HeaderDedupe is a middleware handler that collapses repeated values of the configured
+// response headers into a single comma separated entry just before the response is written.
+// This keeps headers such as Vary clean when several layers append to them.
+type HeaderDedupe struct {
+	// Headers lists the response headers to deduplicate.
+	Headers []string
+}
+
+// NewHeaderDedupe returns a new instance of HeaderDedupe. When no headers are given it
+// deduplicates the Vary header.
+func NewHeaderDedupe(headers ...string) *HeaderDedupe {
+	if len(headers) == 0 {
+		headers = []string{"Vary"}
+	}
+	return &HeaderDedupe{Headers: headers}
+}
+
+func (d *HeaderDedupe) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	rw.(ResponseWriter).Before(func(rw ResponseWriter) {
+		h := rw.Header()
+		for _, name := range d.Headers {
+			if values, ok := h[http.CanonicalHeaderKey(name)]; ok {
+				h.Set(name, dedupeHeaderValues(values))
+			}
+		}
+	})
+
+	next(rw, r)
+}
+
+// dedupeHeaderValues splits comma separated values and joins the unique ones, comparing
+// case-insensitively and keeping the first spelling seen.
+func dedupeHeaderValues(values []string) string {
+	seen := make(map[string]bool)
+	var unique []string
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			part = strings.TrimSpace(part)
+			key := strings.ToLower(part)
+			if part == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			unique = append(unique, part)
+		}
+	}
+	return strings.Join(unique, ", ")
+}
@@ -0,0 +1,42 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHeaderDedupeVary(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	n.Use(NewHeaderDedupe())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Add("Vary", "Accept-Encoding")
+		rw.Header().Add("Vary", "Origin, accept-encoding")
+		rw.Header().Add("Vary", "Origin")
+		rw.Header().Add("X-Other", "a")
+		rw.Header().Add("X-Other", "a")
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	n.ServeHTTP(recorder, (*http.Request)(nil))
+	expect(t, len(recorder.Header()["Vary"]), 1)
+	expect(t, recorder.Header().Get("Vary"), "Accept-Encoding, Origin")
+	expect(t, len(recorder.Header()["X-Other"]), 2)
+}
+
+func TestHeaderDedupeConfigured(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	n.Use(NewHeaderDedupe("Cache-Control"))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Add("Cache-Control", "no-cache")
+		rw.Header().Add("Cache-Control", "no-cache, no-store")
+		rw.Write([]byte("hello"))
+	})
+
+	n.ServeHTTP(recorder, (*http.Request)(nil))
+	expect(t, recorder.Header().Get("Cache-Control"), "no-cache, no-store")
+}
@@ -0,0 +1,71 @@
+package negroni
+
+import (
+	"net/http"
+)
+
+// HeaderSanitizer is a middleware handler that strips or rewrites response headers just before
+// the response is written, e.g. to remove X-Powered-By or to change the domain of cookies set
+// by a proxied backend. It centralizes response header hygiene in one place.
+type HeaderSanitizer struct {
+	// Strip lists the response headers to remove.
+	Strip []string
+	// Rewriters maps a response header to a function applied to each of its values. Values
+	// rewritten to the empty string are removed.
+	Rewriters map[string]func(value string) string
+}
+
+// NewHeaderSanitizer returns a new instance of HeaderSanitizer stripping the given headers
+func NewHeaderSanitizer(strip ...string) *HeaderSanitizer {
+	return &HeaderSanitizer{
+		Strip:     strip,
+		Rewriters: make(map[string]func(string) string),
+	}
+}
+
+// Rewrite registers fn to rewrite the values of header.
+func (hs *HeaderSanitizer) Rewrite(header string, fn func(value string) string) *HeaderSanitizer {
+	hs.Rewriters[http.CanonicalHeaderKey(header)] = fn
+	return hs
+}
+
+func (hs *HeaderSanitizer) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	rw.(ResponseWriter).Before(func(rw ResponseWriter) {
+		h := rw.Header()
+		for _, name := range hs.Strip {
+			h.Del(name)
+		}
+		for name, fn := range hs.Rewriters {
+			values := h[http.CanonicalHeaderKey(name)]
+			if len(values) == 0 {
+				continue
+			}
+			rewritten := values[:0]
+			for _, v := range values {
+				if v = fn(v); v != "" {
+					rewritten = append(rewritten, v)
+				}
+			}
+			if len(rewritten) == 0 {
+				h.Del(name)
+			} else {
+				h[http.CanonicalHeaderKey(name)] = rewritten
+			}
+		}
+	})
+
+	next(rw, r)
+}
+
+// RewriteCookieDomain returns a Set-Cookie rewriter for HeaderSanitizer that sets the domain of
+// every cookie to domain. Values that are not valid cookies are dropped.
+func RewriteCookieDomain(domain string) func(string) string {
+	return func(value string) string {
+		cookies := (&http.Response{Header: http.Header{"Set-Cookie": {value}}}).Cookies()
+		if len(cookies) == 0 {
+			return ""
+		}
+		cookies[0].Domain = domain
+		return cookies[0].String()
+	}
+}
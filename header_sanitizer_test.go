@@ -0,0 +1,40 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHeaderSanitizer(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	hs := NewHeaderSanitizer("X-Powered-By", "Server")
+	hs.Rewrite("Set-Cookie", RewriteCookieDomain("example.com"))
+	hs.Rewrite("X-Internal", func(string) string { return "" })
+
+	n := New()
+	n.Use(hs)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("X-Powered-By", "PHP/5.4")
+		rw.Header().Set("X-Internal", "10.0.0.3")
+		rw.Header().Set("Content-Type", "text/plain")
+		rw.Header().Add("Set-Cookie", "session=abc; Domain=backend.internal; Path=/; HttpOnly")
+		rw.Header().Add("Set-Cookie", "theme=dark")
+		rw.Write([]byte("ok"))
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(recorder, req)
+
+	h := recorder.Header()
+	expect(t, h.Get("X-Powered-By"), "")
+	expect(t, len(h["X-Internal"]), 0)
+	expect(t, h.Get("Content-Type"), "text/plain")
+	expect(t, len(h["Set-Cookie"]), 2)
+	expect(t, h["Set-Cookie"][0], "session=abc; Path=/; Domain=example.com; HttpOnly")
+	expect(t, h["Set-Cookie"][1], "theme=dark; Domain=example.com")
+}
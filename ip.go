@@ -0,0 +1,52 @@
+package negroni
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// NormalizeIP returns a canonical form of a client address so that per-IP logic such as rate
+// limits and allowlists keys consistently. It drops a port and IPv6 brackets, strips zone IDs
+// ("fe80::1%eth0" becomes "fe80::1"), collapses IPv4-mapped IPv6 addresses ("::ffff:1.2.3.4"
+// becomes "1.2.3.4") and formats IPv6 addresses in their shortest form. Strings that are not
+// IP addresses are returned trimmed but otherwise unchanged.
+func NormalizeIP(addr string) string {
+	addr = strings.TrimSpace(addr)
+	host := addr
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if i := strings.IndexByte(host, '%'); i >= 0 {
+		host = host[:i]
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return addr
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.String()
+	}
+	return ip.String()
+}
+
+// trustedClient reports whether the client address of r is one of trusted, a list of IP
+// addresses and CIDR ranges.
+func trustedClient(r *http.Request, trusted []string) bool {
+	ip := net.ParseIP(clientIP(r, false))
+	if ip == nil {
+		return false
+	}
+	for _, t := range trusted {
+		if _, n, err := net.ParseCIDR(t); err == nil {
+			if n.Contains(ip) {
+				return true
+			}
+		} else if addr := net.ParseIP(t); addr != nil && addr.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
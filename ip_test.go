@@ -0,0 +1,21 @@
+package negroni
+
+import "testing"
+
+func TestNormalizeIP(t *testing.T) {
+	for in, out := range map[string]string{
+		"1.2.3.4":              "1.2.3.4",
+		" 1.2.3.4 ":            "1.2.3.4",
+		"1.2.3.4:8080":         "1.2.3.4",
+		"::ffff:1.2.3.4":       "1.2.3.4",
+		"[::ffff:1.2.3.4]:443": "1.2.3.4",
+		"fe80::1%eth0":         "fe80::1",
+		"[fe80::1%25eth0]:80":  "fe80::1",
+		"2001:DB8:0:0:0:0:0:1": "2001:db8::1",
+		"[2001:db8::1]":        "2001:db8::1",
+		"not-an-ip":            "not-an-ip",
+		"":                     "",
+	} {
+		expect(t, NormalizeIP(in), out)
+	}
+}
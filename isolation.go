@@ -0,0 +1,71 @@
+package negroni
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+)
+
+// HandlerPanic describes a panic attributed to a single handler of an isolated stack.
+type HandlerPanic struct {
+	// Index is the position of the handler in Handlers().
+	Index   int
+	Handler Handler
+	// Value is the value the handler panicked with.
+	Value interface{}
+	Stack []byte
+}
+
+func (p *HandlerPanic) Error() string {
+	return fmt.Sprintf("handler %d (%T): %v", p.Index, p.Handler, p.Value)
+}
+
+// IsolatePanics runs every handler of the stack in its own recover scope, so a panic is
+// attributed to the handler that raised it. onPanic decides what happens next: returning true
+// contains the panic and continues with the rest of the chain as if the handler had returned
+// (calling next if it had not yet done so), returning false aborts by re-panicking with the
+// *HandlerPanic, which propagates through outer handlers unchanged to a Recovery or the server.
+//
+// Isolation costs a deferred closure per handler and request, so it is best reserved for
+// debugging or stacks that include untrusted handlers. Passing nil turns it off.
+func (n *Negroni) IsolatePanics(onPanic func(*HandlerPanic) bool) {
+	n.onPanic = onPanic
+	n.rebuild()
+}
+
+// isolate sets up count middleware of the chain starting at m, the one at position index, to
+// run isolated.
+func isolate(m *middleware, index, count int, onPanic func(*HandlerPanic) bool) {
+	for i := 0; i < count; i++ {
+		m.index = index + i
+		m.onPanic = onPanic
+		m = m.next
+	}
+}
+
+func (m middleware) serveIsolated(rw http.ResponseWriter, r *http.Request) {
+	calledNext := false
+	defer func() {
+		err := recover()
+		if err == nil {
+			return
+		}
+		if p, ok := err.(*HandlerPanic); ok {
+			// Raised further down the chain and already attributed.
+			panic(p)
+		}
+
+		p := &HandlerPanic{Index: m.index, Handler: m.handler, Value: err, Stack: debug.Stack()}
+		if !m.onPanic(p) {
+			panic(p)
+		}
+		if !calledNext {
+			m.next.ServeHTTP(rw, r)
+		}
+	}()
+
+	m.handler.ServeHTTP(rw, r, func(rw http.ResponseWriter, r *http.Request) {
+		calledNext = true
+		m.next.ServeHTTP(rw, r)
+	})
+}
@@ -0,0 +1,98 @@
+package negroni
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestIsolatePanicsAttribution(t *testing.T) {
+	var panics []*HandlerPanic
+
+	n := New()
+	n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		next(rw, r)
+	})
+	n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		panic("second")
+	})
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		panic("third")
+	})
+	n.IsolatePanics(func(p *HandlerPanic) bool {
+		panics = append(panics, p)
+		return true
+	})
+
+	n.ServeHTTP(httptest.NewRecorder(), (*http.Request)(nil))
+	expect(t, len(panics), 2)
+	expect(t, panics[0].Index, 1)
+	expect(t, panics[0].Value, "second")
+	expect(t, panics[1].Index, 2)
+	expect(t, panics[1].Value, "third")
+	refute(t, len(panics[1].Stack), 0)
+}
+
+func TestIsolatePanicsAppendedHandlers(t *testing.T) {
+	var panics []*HandlerPanic
+
+	n := New()
+	n.IsolatePanics(func(p *HandlerPanic) bool {
+		panics = append(panics, p)
+		return true
+	})
+	n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		panic("first")
+	})
+	n.UseAll(
+		HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			panic("second")
+		}),
+		Wrap(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			panic("third")
+		})),
+	)
+
+	n.ServeHTTP(httptest.NewRecorder(), (*http.Request)(nil))
+	expect(t, len(panics), 3)
+	for i, value := range []string{"first", "second", "third"} {
+		expect(t, panics[i].Index, i)
+		expect(t, panics[i].Value, value)
+	}
+}
+
+func TestIsolatePanicsAbort(t *testing.T) {
+	buff := bytes.NewBufferString("")
+	rec := NewRecovery()
+	rec.Logger = log.New(buff, "[negroni] ", 0)
+	rec.PrintStack = false
+	reached := false
+
+	n := New()
+	n.Use(rec)
+	n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		next(rw, r)
+	})
+	n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		panic("boom")
+	})
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		reached = true
+	})
+	n.IsolatePanics(func(p *HandlerPanic) bool {
+		return false
+	})
+	// Handlers added after enabling isolation are isolated too.
+	n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {})
+
+	recorder := httptest.NewRecorder()
+	n.ServeHTTP(recorder, (*http.Request)(nil))
+	expect(t, reached, false)
+	expect(t, recorder.Code, http.StatusInternalServerError)
+	if !strings.Contains(buff.String(), "PANIC: handler 2 (negroni.HandlerFunc): boom") {
+		t.Errorf("Expected attributed panic in log, got %q", buff.String())
+	}
+}
@@ -0,0 +1,71 @@
+package negroni
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+)
+
+// LifecycleHandler is implemented by handlers that need process level setup and teardown,
+// such as opening and flushing a metrics exporter. RunWithContext calls Start on every such
+// handler before serving and Stop once the server has shut down.
+type LifecycleHandler interface {
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// RunWithContext runs the negroni stack as an HTTP server until ctx is cancelled, then shuts
+// the server down gracefully. Handlers implementing LifecycleHandler are started in stack order
+// before the server listens and stopped in reverse order afterwards. If a handler fails to
+// start, the handlers already started are stopped and the error is returned.
+func (n *Negroni) RunWithContext(ctx context.Context, addr string) error {
+	started, err := n.startHandlers(ctx)
+	if err != nil {
+		n.stopHandlers(context.Background(), started)
+		return err
+	}
+
+	l := log.New(os.Stdout, "[negroni] ", 0)
+	l.Printf("listening on %s", addr)
+
+	srv := &http.Server{Addr: addr}
+	errc := make(chan error, 1)
+	go func() {
+		errc <- n.RunServer(srv)
+	}()
+
+	select {
+	case err = <-errc:
+	case <-ctx.Done():
+		err = srv.Shutdown(context.Background())
+	}
+
+	if stopErr := n.stopHandlers(context.Background(), started); err == nil {
+		err = stopErr
+	}
+	return err
+}
+
+func (n *Negroni) startHandlers(ctx context.Context) ([]LifecycleHandler, error) {
+	var started []LifecycleHandler
+	for _, h := range n.handlers {
+		if lh, ok := h.(LifecycleHandler); ok {
+			if err := lh.Start(ctx); err != nil {
+				return started, err
+			}
+			started = append(started, lh)
+		}
+	}
+	return started, nil
+}
+
+func (n *Negroni) stopHandlers(ctx context.Context, started []LifecycleHandler) error {
+	var err error
+	for i := len(started) - 1; i >= 0; i-- {
+		if stopErr := started[i].Stop(ctx); err == nil {
+			err = stopErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,64 @@
+package negroni
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+type lifecycleRecorder struct {
+	name     string
+	events   *[]string
+	startErr error
+}
+
+func (h *lifecycleRecorder) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	next(rw, r)
+}
+
+func (h *lifecycleRecorder) Start(ctx context.Context) error {
+	*h.events = append(*h.events, "start "+h.name)
+	return h.startErr
+}
+
+func (h *lifecycleRecorder) Stop(ctx context.Context) error {
+	*h.events = append(*h.events, "stop "+h.name)
+	return nil
+}
+
+func TestRunWithContextLifecycle(t *testing.T) {
+	var events []string
+	ctx, cancel := context.WithCancel(context.Background())
+
+	n := New()
+	n.Use(&lifecycleRecorder{name: "a", events: &events})
+	n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		next(rw, r)
+	})
+	n.Use(&lifecycleRecorder{name: "b", events: &events})
+	cancel()
+
+	err := n.RunWithContext(ctx, "127.0.0.1:0")
+	expect(t, err, nil)
+	expect(t, len(events), 4)
+	expect(t, events[0], "start a")
+	expect(t, events[1], "start b")
+	expect(t, events[2], "stop b")
+	expect(t, events[3], "stop a")
+}
+
+func TestRunWithContextStartError(t *testing.T) {
+	var events []string
+	startErr := errors.New("exporter unavailable")
+
+	n := New()
+	n.Use(&lifecycleRecorder{name: "a", events: &events})
+	n.Use(&lifecycleRecorder{name: "b", events: &events, startErr: startErr})
+	n.Use(&lifecycleRecorder{name: "c", events: &events})
+
+	err := n.RunWithContext(context.Background(), "127.0.0.1:0")
+	expect(t, err, startErr)
+	expect(t, len(events), 3)
+	expect(t, events[2], "stop a")
+}
@@ -0,0 +1,46 @@
+package negroni
+
+import (
+	"context"
+	"sync"
+)
+
+type logFieldsKey struct{}
+
+// LogField is a key/value pair added to a request's structured log entry.
+type LogField struct {
+	Key   string
+	Value interface{}
+}
+
+type logFields struct {
+	mu     sync.Mutex
+	fields []LogField
+}
+
+func withLogFields(ctx context.Context) context.Context {
+	return context.WithValue(ctx, logFieldsKey{}, &logFields{})
+}
+
+// AddLogField adds a key/value pair to the structured log entry of the request ctx belongs to.
+// It does nothing unless a Logger in Structured mode is in the stack.
+func AddLogField(ctx context.Context, key string, value interface{}) {
+	f, ok := ctx.Value(logFieldsKey{}).(*logFields)
+	if !ok {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fields = append(f.fields, LogField{key, value})
+}
+
+// LogFields returns the fields added with AddLogField for the request ctx belongs to.
+func LogFields(ctx context.Context) []LogField {
+	f, ok := ctx.Value(logFieldsKey{}).(*logFields)
+	if !ok {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]LogField(nil), f.fields...)
+}
@@ -0,0 +1,20 @@
+package negroni
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLogFields(t *testing.T) {
+	AddLogField(context.Background(), "ignored", true)
+	expect(t, len(LogFields(context.Background())), 0)
+
+	ctx := withLogFields(context.Background())
+	AddLogField(ctx, "db", "users")
+	AddLogField(ctx, "rows", 3)
+
+	fields := LogFields(ctx)
+	expect(t, len(fields), 2)
+	expect(t, fields[0], LogField{"db", "users"})
+	expect(t, fields[1], LogField{"rows", 3})
+}
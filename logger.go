@@ -1,29 +1,357 @@
 package negroni
 
 import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
 	"log"
+	"math/rand"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
 	"time"
 )
 
+// LoggerDefaultFormat is the template Logger uses for the line written when a request completes.
+const LoggerDefaultFormat = "Completed {{.Status}} {{.StatusText}} in {{.Duration}} ({{.Size}} bytes)" +
+	"{{with .QueueTime}} queued {{.}}{{end}}{{with .ClientIP}} from {{.}}{{end}}" +
+	"{{range .Spans}} {{.Name}}={{.Duration}}{{end}}"
+
+// LoggerEntry holds the values available to a Logger Format template.
+type LoggerEntry struct {
+	StartTime  time.Time
+	Status     int
+	StatusText string
+	Size       int
+	Duration   time.Duration
+	Hostname   string
+	Method     string
+	Path       string
+	RemoteAddr string
+	// ClientIP is the client address, taken from proxy headers when the Logger's TrustProxy
+	// is set.
+	ClientIP  string
+	RequestID string
+	// QueueTime is the time spent waiting for limiters, when the Logger's LogQueueTime is
+	// set.
+	QueueTime time.Duration
+	// Spans holds the sub-operation timings recorded with StartSpan.
+	Spans   []Span
+	Request *http.Request
+}
+
 // Logger is a middleware handler that logs the request as it goes in and the response as it goes out.
 type Logger struct {
 	// Logger inherits from log.Logger used to log messages with the Logger middleware
 	*log.Logger
+	// LogOnlyErrors restricts logging to responses with a 4xx or 5xx status. Both the
+	// Started and Completed lines are written after the response in this mode.
+	LogOnlyErrors bool
+	// LogProto adds the request protocol, e.g. HTTP/1.1, to the Started line.
+	LogProto bool
+	// LogTLS adds the negotiated TLS version and cipher suite to the Started line for
+	// requests served over TLS.
+	LogTLS bool
+	// Structured writes a single key=value entry per request once it completes, instead of
+	// the Started and Completed lines. The entry carries the method, path, status, size,
+	// duration, request ID, outcome, spans and any fields added with AddLogField. It avoids
+	// interleaved lines under concurrency and is the recommended mode for log aggregation.
+	Structured bool
+	// LogQuery adds the raw query string to the logged path.
+	LogQuery bool
+	// RedactParams lists query parameters whose values are replaced with REDACTED when
+	// LogQuery is set, e.g. tokens or signatures.
+	RedactParams []string
+	// LogRequestID prefixes the Started and Completed lines with the request ID, as set by
+	// RequestID or RequestAttributer, e.g. "[1f2e...] Started GET /".
+	LogRequestID bool
+	// JSON writes a single JSON object per request once it completes, instead of the
+	// Started and Completed lines. It carries the method, path, status, duration in
+	// milliseconds, response size and start time in RFC 3339 format, named after JSONFields.
+	// The record goes through Logger, so its prefix and flags still apply: NewLogger's
+	// "[negroni] " prefix makes the lines invalid JSON. Use NewLoggerJSON, or a log.Logger
+	// with no prefix or flags, for JSON output.
+	JSON bool
+	// JSONFields overrides the field names used in JSON mode. Fields left empty use the
+	// names in DefaultLoggerJSONFields.
+	JSONFields LoggerJSONFields
+	// LogQueueTime adds the time the request waited in limiters such as ConcurrencyLimit,
+	// see QueueTime, to the Completed line and the Structured entry.
+	LogQueueTime bool
+	// TrustProxy takes the client address logged from the X-Forwarded-For or X-Real-IP
+	// headers instead of the connection. With several X-Forwarded-For hops the left-most
+	// public address is used. Only set it when a trusted proxy in front of the server sets
+	// or strips these headers, since clients can otherwise put any address in them.
+	TrustProxy bool
+	// SkipPaths lists request paths that are never logged, such as health checks.
+	SkipPaths []string
+	// Skip optionally decides whether a request is left out of the log, e.g. by path prefix.
+	Skip func(r *http.Request) bool
+	// SampleRates maps request paths to the fraction of their requests that are logged,
+	// e.g. 0.01 for /metrics. Other paths are always logged. The decision uses the seed
+	// stored by RequestSeed when there is one, so it agrees with other sampling decisions
+	// for the request, and is random otherwise.
+	SampleRates map[string]float64
+	// Format is the text/template used for the line written when a request completes, with
+	// a LoggerEntry as data. It is compiled on first use, so changes made after the Logger
+	// has served a request have no effect. An empty Format means LoggerDefaultFormat.
+	Format string
+
+	templateOnce sync.Once
+	template     *template.Template
 }
 
 // NewLogger returns a new Logger instance
 func NewLogger() *Logger {
-	return &Logger{log.New(os.Stdout, "[negroni] ", 0)}
+	return &Logger{Logger: log.New(os.Stdout, "[negroni] ", 0), Format: LoggerDefaultFormat}
 }
 
 func (l *Logger) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	// Negroni wraps the writer before the chain runs, but a Logger called from elsewhere
+	// may get a plain http.ResponseWriter. Wrap it for this request so the status and size
+	// can still be logged; writers that are already wrapped are used as is.
+	res, ok := rw.(ResponseWriter)
+	if !ok {
+		res = NewResponseWriter(rw)
+	}
+
+	if !l.shouldLog(r) {
+		next(res, r)
+		return
+	}
+	r = r.WithContext(withQueueTime(withSpans(r.Context())))
+
+	if l.Structured {
+		l.serveStructured(res, r, next)
+		return
+	}
+	if l.JSON {
+		l.serveJSON(res, r, next)
+		return
+	}
+
+	start := time.Now()
+	if !l.LogOnlyErrors {
+		l.logStarted(r)
+	}
+
+	next(res, r)
+
+	if l.LogOnlyErrors {
+		if res.Status() < http.StatusBadRequest {
+			return
+		}
+		l.logStarted(r)
+	}
+	l.logCompleted(r, res, start)
+}
+
+func (l *Logger) logCompleted(r *http.Request, res ResponseWriter, start time.Time) {
+	status := finalStatus(res)
+	entry := LoggerEntry{
+		StartTime:  start,
+		Status:     status,
+		StatusText: http.StatusText(status),
+		Size:       res.Size(),
+		Duration:   time.Since(start),
+		Hostname:   r.Host,
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		ClientIP:   clientIP(r, l.TrustProxy),
+		RequestID:  RequestIDFromContext(r.Context()),
+		QueueTime:  l.queueTime(r),
+		Spans:      Spans(r.Context()),
+		Request:    r,
+	}
+
+	var buf bytes.Buffer
+	if err := l.compiledFormat().Execute(&buf, entry); err != nil {
+		l.Printf("log format: %v", err)
+		return
+	}
+	l.Println(l.requestIDPrefix(r) + buf.String())
+}
+
+// shouldLog reports whether r is to be logged according to SkipPaths, Skip and SampleRates.
+func (l *Logger) shouldLog(r *http.Request) bool {
+	if containsString(l.SkipPaths, r.URL.Path) || (l.Skip != nil && l.Skip(r)) {
+		return false
+	}
+
+	rate, ok := l.SampleRates[r.URL.Path]
+	if !ok {
+		return true
+	}
+	if seed, ok := r.Context().Value(seedKey{}).(uint64); ok {
+		return float64(seed%10000) < rate*10000
+	}
+	return rand.Float64() < rate
+}
+
+func (l *Logger) queueTime(r *http.Request) time.Duration {
+	if !l.LogQueueTime {
+		return 0
+	}
+	return QueueTime(r.Context())
+}
+
+func (l *Logger) requestIDPrefix(r *http.Request) string {
+	if !l.LogRequestID {
+		return ""
+	}
+	if id := RequestIDFromContext(r.Context()); id != "" {
+		return "[" + id + "] "
+	}
+	return ""
+}
+
+// compiledFormat parses Format the first time it is needed, falling back to
+// LoggerDefaultFormat if Format is empty or invalid.
+func (l *Logger) compiledFormat() *template.Template {
+	l.templateOnce.Do(func() {
+		format := l.Format
+		if format == "" {
+			format = LoggerDefaultFormat
+		}
+		tmpl, err := template.New("negroni").Parse(format)
+		if err != nil {
+			l.Printf("log format: %v", err)
+			tmpl = template.Must(template.New("negroni").Parse(LoggerDefaultFormat))
+		}
+		l.template = tmpl
+	})
+	return l.template
+}
+
+func (l *Logger) logStarted(r *http.Request) {
+	line := "Started " + r.Method + " " + r.URL.Path
+	if q := l.query(r); q != "" {
+		line += "?" + q
+	}
+	if l.LogProto {
+		line += " " + r.Proto
+	}
+	if l.LogTLS && r.TLS != nil {
+		line += " " + tls.VersionName(r.TLS.Version) + " " + tls.CipherSuiteName(r.TLS.CipherSuite)
+	}
+	l.Println(l.requestIDPrefix(r) + line)
+}
+
+func (l *Logger) serveStructured(res ResponseWriter, r *http.Request, next http.HandlerFunc) {
 	start := time.Now()
-	l.Printf("Started %s %s", r.Method, r.URL.Path)
+	ctx := withLogFields(r.Context())
+
+	next(res, r.WithContext(ctx))
 
-	next(rw, r)
+	status := finalStatus(res)
+	if l.LogOnlyErrors && status < http.StatusBadRequest {
+		return
+	}
+
+	var buf bytes.Buffer
+	writeLogField(&buf, "method", r.Method)
+	writeLogField(&buf, "path", r.URL.Path)
+	if q := l.query(r); q != "" {
+		writeLogField(&buf, "query", q)
+	}
+	if l.LogProto {
+		writeLogField(&buf, "proto", r.Proto)
+	}
+	if l.LogTLS && r.TLS != nil {
+		writeLogField(&buf, "tls_version", tls.VersionName(r.TLS.Version))
+		writeLogField(&buf, "tls_cipher", tls.CipherSuiteName(r.TLS.CipherSuite))
+	}
+	writeLogField(&buf, "status", status)
+	writeLogField(&buf, "size", res.Size())
+	writeLogField(&buf, "duration", time.Since(start))
+	if l.LogQueueTime {
+		writeLogField(&buf, "queue_time", QueueTime(ctx))
+	}
+	if id := logRequestID(r, res); id != "" {
+		writeLogField(&buf, "request_id", id)
+	}
+	writeLogField(&buf, "outcome", outcome(status))
+	for _, s := range Spans(ctx) {
+		writeLogField(&buf, "span_"+s.Name, s.Duration)
+	}
+	for _, f := range LogFields(ctx) {
+		writeLogField(&buf, f.Key, f.Value)
+	}
+	l.Println(buf.String())
+}
+
+// query returns the raw query string to log, with the values of RedactParams masked.
+func (l *Logger) query(r *http.Request) string {
+	if !l.LogQuery || r.URL.RawQuery == "" {
+		return ""
+	}
+	if len(l.RedactParams) == 0 {
+		return r.URL.RawQuery
+	}
+
+	pairs := strings.Split(r.URL.RawQuery, "&")
+	for i, pair := range pairs {
+		rawKey := pair
+		if j := strings.Index(pair, "="); j >= 0 {
+			rawKey = pair[:j]
+		}
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			key = rawKey
+		}
+		for _, redact := range l.RedactParams {
+			if key == redact {
+				pairs[i] = rawKey + "=REDACTED"
+				break
+			}
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// logRequestID finds the request ID from RequestID, RequestAttributes or the X-Request-ID
+// request or response header.
+func logRequestID(r *http.Request, res ResponseWriter) string {
+	if id := RequestIDFromContext(r.Context()); id != "" {
+		return id
+	}
+	if id := r.Header.Get("X-Request-ID"); id != "" {
+		return id
+	}
+	return res.Header().Get("X-Request-ID")
+}
+
+func outcome(status int) string {
+	switch {
+	case status >= 500:
+		return "server_error"
+	case status >= 400:
+		return "client_error"
+	}
+	return "success"
+}
+
+// writeLogField appends key=value to buf, quoting the value when it contains spaces,
+// quotes or equals signs.
+func writeLogField(buf *bytes.Buffer, key string, value interface{}) {
+	if buf.Len() > 0 {
+		buf.WriteByte(' ')
+	}
+	v := fmt.Sprint(value)
+	if v == "" || strings.ContainsAny(v, " \t\"=") {
+		v = strconv.Quote(v)
+	}
+	buf.WriteString(key)
+	buf.WriteByte('=')
+	buf.WriteString(v)
+}
 
-	res := rw.(ResponseWriter)
-	l.Printf("Completed %v %s in %v", res.Status(), http.StatusText(res.Status()), time.Since(start))
+// Role identifies Logger to Negroni.Validate.
+func (l *Logger) Role() HandlerRole {
+	return RoleLogger
 }
@@ -0,0 +1,98 @@
+package negroni
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+// LoggerJSONFields names the fields of the records written by a Logger in JSON mode, so they
+// can match an existing schema.
+type LoggerJSONFields struct {
+	Method     string
+	Path       string
+	Status     string
+	Size       string
+	DurationMs string
+	StartedAt  string
+}
+
+// DefaultLoggerJSONFields are the field names used for the fields a Logger's JSONFields leave
+// empty.
+var DefaultLoggerJSONFields = LoggerJSONFields{
+	Method:     "method",
+	Path:       "path",
+	Status:     "status",
+	Size:       "size",
+	DurationMs: "duration_ms",
+	StartedAt:  "started_at",
+}
+
+// NewLoggerJSON returns a new Logger instance that writes one JSON object per completed
+// request to stdout, with no prefix
+func NewLoggerJSON() *Logger {
+	l := NewLogger()
+	l.Logger = log.New(os.Stdout, "", 0)
+	l.JSON = true
+	l.JSONFields = DefaultLoggerJSONFields
+	return l
+}
+
+func (l *Logger) serveJSON(res ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	start := time.Now()
+
+	next(res, r)
+
+	status := finalStatus(res)
+	if l.LogOnlyErrors && status < http.StatusBadRequest {
+		return
+	}
+
+	names := l.jsonFields()
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	writeJSONField(&buf, names.Method, r.Method)
+	writeJSONField(&buf, names.Path, r.URL.Path)
+	writeJSONField(&buf, names.Status, status)
+	writeJSONField(&buf, names.Size, res.Size())
+	writeJSONField(&buf, names.DurationMs, float64(time.Since(start))/float64(time.Millisecond))
+	writeJSONField(&buf, names.StartedAt, start.Format(time.RFC3339))
+	buf.WriteByte('}')
+	l.Println(buf.String())
+}
+
+// jsonFields returns JSONFields with the empty names filled in from DefaultLoggerJSONFields.
+func (l *Logger) jsonFields() LoggerJSONFields {
+	names := l.JSONFields
+	for _, f := range []struct {
+		name *string
+		def  string
+	}{
+		{&names.Method, DefaultLoggerJSONFields.Method},
+		{&names.Path, DefaultLoggerJSONFields.Path},
+		{&names.Status, DefaultLoggerJSONFields.Status},
+		{&names.Size, DefaultLoggerJSONFields.Size},
+		{&names.DurationMs, DefaultLoggerJSONFields.DurationMs},
+		{&names.StartedAt, DefaultLoggerJSONFields.StartedAt},
+	} {
+		if *f.name == "" {
+			*f.name = f.def
+		}
+	}
+	return names
+}
+
+func writeJSONField(buf *bytes.Buffer, key string, value interface{}) {
+	if buf.Len() > 1 {
+		buf.WriteByte(',')
+	}
+	k, _ := json.Marshal(key)
+	v, _ := json.Marshal(value)
+	buf.Write(k)
+	buf.WriteByte(':')
+	buf.Write(v)
+}
@@ -0,0 +1,80 @@
+package negroni
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func serveLoggerJSON(t *testing.T, l *Logger) map[string]interface{} {
+	buff := bytes.NewBufferString("")
+	l.Logger = log.New(buff, "", 0)
+
+	n := New()
+	n.Use(l)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusCreated)
+	})
+
+	req, err := http.NewRequest("POST", "http://localhost:3000/orders", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(httptest.NewRecorder(), req)
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buff.Bytes(), &record); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buff.String(), err)
+	}
+	return record
+}
+
+func TestLoggerJSON(t *testing.T) {
+	record := serveLoggerJSON(t, NewLoggerJSON())
+	expect(t, len(record), 6)
+	expect(t, record["method"], "POST")
+	expect(t, record["path"], "/orders")
+	expect(t, record["status"], float64(http.StatusCreated))
+	expect(t, record["size"], float64(0))
+	_, ok := record["duration_ms"].(float64)
+	expect(t, ok, true)
+	_, err := time.Parse(time.RFC3339, record["started_at"].(string))
+	expect(t, err, nil)
+}
+
+func TestLoggerJSONFieldNames(t *testing.T) {
+	l := NewLoggerJSON()
+	l.JSONFields = LoggerJSONFields{
+		Method:     "http.method",
+		Path:       "url.path",
+		Status:     "http.status_code",
+		Size:       "http.response.bytes",
+		DurationMs: "event.duration_ms",
+		StartedAt:  "@timestamp",
+	}
+
+	record := serveLoggerJSON(t, l)
+	expect(t, record["http.method"], "POST")
+	expect(t, record["url.path"], "/orders")
+	expect(t, record["http.status_code"], float64(http.StatusCreated))
+	_, ok := record["@timestamp"]
+	expect(t, ok, true)
+}
+
+func TestLoggerJSONPartialFieldNames(t *testing.T) {
+	l := NewLogger()
+	l.JSON = true
+	l.JSONFields = LoggerJSONFields{Method: "verb"}
+
+	record := serveLoggerJSON(t, l)
+	expect(t, len(record), 6)
+	expect(t, record["verb"], "POST")
+	expect(t, record["path"], "/orders")
+	expect(t, record["status"], float64(http.StatusCreated))
+	_, ok := record[""]
+	expect(t, ok, false)
+}
@@ -2,9 +2,11 @@ package negroni
 
 import (
 	"bytes"
+	"crypto/tls"
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -31,3 +33,377 @@ func Test_Logger(t *testing.T) {
 	expect(t, recorder.Code, http.StatusNotFound)
 	refute(t, len(buff.String()), 0)
 }
+
+func TestLoggerLogOnlyErrors(t *testing.T) {
+	buff := bytes.NewBufferString("")
+
+	l := NewLogger()
+	l.Logger = log.New(buff, "[negroni] ", 0)
+	l.LogOnlyErrors = true
+
+	status := http.StatusOK
+	n := New()
+	n.Use(l)
+	n.UseHandler(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(status)
+	}))
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/foobar", nil)
+	if err != nil {
+		t.Error(err)
+	}
+
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, buff.String(), "")
+
+	status = http.StatusInternalServerError
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	if !strings.HasPrefix(buff.String(), "[negroni] Started GET /foobar\n[negroni] Completed 500") {
+		t.Errorf("Unexpected log output %q", buff.String())
+	}
+}
+
+func TestLoggerProtoAndTLS(t *testing.T) {
+	buff := bytes.NewBufferString("")
+
+	l := NewLogger()
+	l.Logger = log.New(buff, "[negroni] ", 0)
+	l.LogProto = true
+	l.LogTLS = true
+
+	n := New()
+	n.Use(l)
+
+	req, err := http.NewRequest("GET", "https://localhost:3000/foobar", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.TLS = &tls.ConnectionState{
+		Version:     tls.VersionTLS12,
+		CipherSuite: tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	}
+
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, strings.HasPrefix(buff.String(), "[negroni] Started GET /foobar HTTP/1.1 TLS 1.2 TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256\n"), true)
+
+	buff.Reset()
+	req.TLS = nil
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, strings.HasPrefix(buff.String(), "[negroni] Started GET /foobar HTTP/1.1\n"), true)
+}
+
+func TestLoggerStructured(t *testing.T) {
+	buff := bytes.NewBufferString("")
+
+	l := NewLogger()
+	l.Logger = log.New(buff, "[negroni] ", 0)
+	l.Structured = true
+
+	n := New()
+	n.Use(l)
+	n.UseHandler(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		AddLogField(r.Context(), "user", 42)
+		AddLogField(r.Context(), "note", "two words")
+		rw.WriteHeader(http.StatusNotFound)
+		rw.Write([]byte("nope"))
+	}))
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/foobar", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("X-Request-ID", "abc123")
+
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	lines := strings.Split(strings.TrimSpace(buff.String()), "\n")
+	expect(t, len(lines), 1)
+
+	line := lines[0]
+	for _, field := range []string{
+		"[negroni] method=GET path=/foobar status=404 size=4 duration=",
+		" request_id=abc123 outcome=client_error user=42 note=\"two words\"",
+	} {
+		if !strings.Contains(line, field) {
+			t.Errorf("Expected %q in log entry %q", field, line)
+		}
+	}
+}
+
+func TestLoggerQuery(t *testing.T) {
+	buff := bytes.NewBufferString("")
+
+	l := NewLogger()
+	l.Logger = log.New(buff, "[negroni] ", 0)
+
+	n := New()
+	n.Use(l)
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/foobar?page=2&token=s3cret&sig&q=a%20b", nil)
+	if err != nil {
+		t.Error(err)
+	}
+
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, strings.HasPrefix(buff.String(), "[negroni] Started GET /foobar\n"), true)
+
+	buff.Reset()
+	l.LogQuery = true
+	l.RedactParams = []string{"token", "sig"}
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, strings.HasPrefix(buff.String(), "[negroni] Started GET /foobar?page=2&token=REDACTED&sig=REDACTED&q=a%20b\n"), true)
+}
+
+func TestLoggerPlainResponseWriter(t *testing.T) {
+	buff := bytes.NewBufferString("")
+	recorder := httptest.NewRecorder()
+
+	l := NewLogger()
+	l.Logger = log.New(buff, "[negroni] ", 0)
+	l.Structured = true
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/foobar", nil)
+	if err != nil {
+		t.Error(err)
+	}
+
+	// Called directly, outside a Negroni stack, with an http.ResponseWriter.
+	l.ServeHTTP(recorder, req, func(rw http.ResponseWriter, r *http.Request) {
+		_, ok := rw.(ResponseWriter)
+		expect(t, ok, true)
+		rw.WriteHeader(http.StatusTeapot)
+		rw.Write([]byte("short"))
+	})
+	expect(t, recorder.Code, http.StatusTeapot)
+	expect(t, strings.Contains(buff.String(), "status=418 size=5 "), true)
+}
+
+func TestLoggerWrappedResponseWriter(t *testing.T) {
+	l := NewLogger()
+	l.Logger = log.New(bytes.NewBufferString(""), "[negroni] ", 0)
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/foobar", nil)
+	if err != nil {
+		t.Error(err)
+	}
+
+	res := NewResponseWriter(httptest.NewRecorder())
+	l.ServeHTTP(res, req, func(rw http.ResponseWriter, r *http.Request) {
+		expect(t, rw, http.ResponseWriter(res))
+		rw.Write([]byte("hello"))
+	})
+	expect(t, res.Size(), 5)
+}
+
+func TestLoggerFormat(t *testing.T) {
+	buff := bytes.NewBufferString("")
+
+	l := NewLogger()
+	l.Logger = log.New(buff, "", 0)
+	l.Format = "{{.RemoteAddr}} {{.Hostname}} {{.Method}} {{.Path}} {{.Status}}"
+
+	n := New()
+	n.Use(l)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusAccepted)
+	})
+
+	req, err := http.NewRequest("POST", "http://example.com/jobs", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.RemoteAddr = "10.0.0.1:1234"
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, buff.String(), "Started POST /jobs\n10.0.0.1:1234 example.com POST /jobs 202\n")
+}
+
+func TestLoggerDefaultFormat(t *testing.T) {
+	buff := bytes.NewBufferString("")
+
+	// A Logger built without NewLogger has no Format and uses the default.
+	l := &Logger{Logger: log.New(buff, "", 0)}
+
+	n := New()
+	n.Use(l)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusNotFound)
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/foobar", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, strings.HasPrefix(buff.String(), "Started GET /foobar\nCompleted 404 Not Found in "), true)
+}
+
+func TestLoggerSize(t *testing.T) {
+	buff := bytes.NewBufferString("")
+
+	l := NewLogger()
+	l.Logger = log.New(buff, "", 0)
+
+	n := New()
+	n.Use(l)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		// No WriteHeader: the status defaults to 200.
+		rw.Write([]byte("hello "))
+		rw.Write([]byte("world"))
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/foobar", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	lines := strings.Split(strings.TrimSpace(buff.String()), "\n")
+	expect(t, len(lines), 2)
+	expect(t, strings.HasPrefix(lines[1], "Completed 200 OK in "), true)
+	expect(t, strings.HasSuffix(lines[1], " (11 bytes)"), true)
+}
+
+func TestLoggerLogRequestID(t *testing.T) {
+	buff := bytes.NewBufferString("")
+
+	l := NewLogger()
+	l.Logger = log.New(buff, "", 0)
+	l.LogRequestID = true
+
+	n := New(NewRequestID(), l)
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/foobar", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("X-Request-ID", "abc-123")
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	lines := strings.Split(strings.TrimSpace(buff.String()), "\n")
+	expect(t, len(lines), 2)
+	expect(t, lines[0], "[abc-123] Started GET /foobar")
+	expect(t, strings.HasPrefix(lines[1], "[abc-123] Completed 200 OK in "), true)
+}
+
+func TestLoggerSampleRates(t *testing.T) {
+	buff := bytes.NewBufferString("")
+
+	l := NewLogger()
+	l.Logger = log.New(buff, "", 0)
+	l.SampleRates = map[string]float64{"/metrics": 0.1}
+
+	n := New()
+	n.Use(l)
+
+	serve := func(path string, times int) int {
+		buff.Reset()
+		req, err := http.NewRequest("GET", "http://localhost:3000"+path, nil)
+		if err != nil {
+			t.Error(err)
+		}
+		for i := 0; i < times; i++ {
+			n.ServeHTTP(httptest.NewRecorder(), req)
+		}
+		return strings.Count(buff.String(), "Started ")
+	}
+
+	expect(t, serve("/orders", 100), 100)
+	if logged := serve("/metrics", 2000); logged < 120 || logged > 280 {
+		t.Errorf("Expected about 200 of 2000 sampled requests to be logged, got %d", logged)
+	}
+}
+
+func TestLoggerSampleRatesFromSeed(t *testing.T) {
+	buff := bytes.NewBufferString("")
+
+	l := NewLogger()
+	l.Logger = log.New(buff, "", 0)
+	l.SampleRates = map[string]float64{"/metrics": 0.5}
+
+	n := New(NewRequestSeed(), l)
+
+	// The decision follows the request ID, so it is the same every time.
+	for _, id := range []string{"a", "b", "c", "d"} {
+		buff.Reset()
+		req, err := http.NewRequest("GET", "http://localhost:3000/metrics", nil)
+		if err != nil {
+			t.Error(err)
+		}
+		req.Header.Set("X-Request-ID", id)
+		n.ServeHTTP(httptest.NewRecorder(), req)
+		first := strings.Count(buff.String(), "Started ")
+		n.ServeHTTP(httptest.NewRecorder(), req)
+		expect(t, strings.Count(buff.String(), "Started "), 2*first)
+	}
+}
+
+func TestLoggerSkip(t *testing.T) {
+	buff := bytes.NewBufferString("")
+	called := 0
+
+	l := NewLogger()
+	l.Logger = log.New(buff, "", 0)
+	l.SkipPaths = []string{"/healthz"}
+	l.Skip = func(r *http.Request) bool { return strings.HasPrefix(r.URL.Path, "/metrics/") }
+
+	n := New()
+	n.Use(l)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		called++
+	})
+
+	for _, path := range []string{"/healthz", "/metrics/cpu", "/orders"} {
+		req, err := http.NewRequest("GET", "http://localhost:3000"+path, nil)
+		if err != nil {
+			t.Error(err)
+		}
+		n.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	expect(t, called, 3)
+	expect(t, strings.Count(buff.String(), "Started "), 1)
+	expect(t, strings.HasPrefix(buff.String(), "Started GET /orders\n"), true)
+}
+
+func TestLoggerClientIP(t *testing.T) {
+	buff := bytes.NewBufferString("")
+
+	l := NewLogger()
+	l.Logger = log.New(buff, "", 0)
+
+	n := New()
+	n.Use(l)
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.RemoteAddr = "10.0.0.1:4567"
+	req.Header.Set("X-Forwarded-For", "10.0.0.5, 203.0.113.7")
+
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, strings.HasSuffix(buff.String(), " from 10.0.0.1\n"), true)
+
+	buff.Reset()
+	l.TrustProxy = true
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, strings.HasSuffix(buff.String(), " from 203.0.113.7\n"), true)
+}
+
+func TestLoggerFormatClientIP(t *testing.T) {
+	buff := bytes.NewBufferString("")
+
+	l := NewLogger()
+	l.Logger = log.New(buff, "", 0)
+	l.TrustProxy = true
+	l.Format = "{{.ClientIP}} {{.RemoteAddr}}"
+
+	n := New()
+	n.Use(l)
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.RemoteAddr = "10.0.0.1:4567"
+	req.Header.Set("X-Real-IP", "203.0.113.7")
+
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, buff.String(), "Started GET /\n203.0.113.7 10.0.0.1:4567\n")
+}
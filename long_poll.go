@@ -0,0 +1,131 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// LongPoll is a middleware handler for long-polling endpoints. It gives the request context a
+// deadline of Timeout, which the handler waits on alongside its events. If the deadline passes
+// before the handler has written anything, the response is ended with TimeoutStatus.
+//
+// To keep proxies from dropping the idle connection, a handler can commit the response with
+// WriteHeader after setting its headers and before it starts waiting. LongPoll then writes and
+// flushes Keepalive every Interval until the handler writes its body. The default keepalive
+// is a newline, which JSON clients ignore.
+type LongPoll struct {
+	// Timeout is how long the handler may wait for an event.
+	Timeout time.Duration
+	// Interval is the time between keepalives. Zero disables them.
+	Interval time.Duration
+	// Keepalive is written to keep the connection open.
+	Keepalive []byte
+	// TimeoutStatus is the status sent when the deadline passes before anything was written.
+	TimeoutStatus int
+}
+
+// NewLongPoll returns a new instance of LongPoll waiting up to timeout, sending a newline every
+// interval and answering timeouts with 204 No Content
+func NewLongPoll(timeout, interval time.Duration) *LongPoll {
+	return &LongPoll{
+		Timeout:       timeout,
+		Interval:      interval,
+		Keepalive:     []byte("\n"),
+		TimeoutStatus: http.StatusNoContent,
+	}
+}
+
+func (lp *LongPoll) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	ctx, cancel := WithMaxDeadline(r.Context(), lp.Timeout)
+	defer cancel()
+
+	pw := &longPollWriter{ResponseWriter: rw.(ResponseWriter)}
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	if lp.Interval > 0 {
+		go func() {
+			defer close(stopped)
+			ticker := time.NewTicker(lp.Interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					pw.keepalive(lp.Keepalive)
+				case <-stop:
+					return
+				}
+			}
+		}()
+	} else {
+		close(stopped)
+	}
+
+	func() {
+		defer func() {
+			close(stop)
+			<-stopped
+		}()
+		next(pw, r.WithContext(ctx))
+	}()
+
+	if !pw.ResponseWriter.Written() && ctx.Err() == context.DeadlineExceeded {
+		pw.ResponseWriter.WriteHeader(lp.TimeoutStatus)
+	}
+}
+
+// longPollWriter serializes the handler's writes with the keepalives, which are only sent
+// once the handler has committed the response and until it writes the body.
+type longPollWriter struct {
+	ResponseWriter
+	mu   sync.Mutex
+	body bool
+}
+
+func (pw *longPollWriter) WriteHeader(s int) {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	pw.ResponseWriter.WriteHeader(s)
+}
+
+func (pw *longPollWriter) Write(b []byte) (int, error) {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	pw.body = true
+	return pw.ResponseWriter.Write(b)
+}
+
+func (pw *longPollWriter) Flush() {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	pw.ResponseWriter.Flush()
+}
+
+func (pw *longPollWriter) Written() bool {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	return pw.ResponseWriter.Written()
+}
+
+func (pw *longPollWriter) Status() int {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	return pw.ResponseWriter.Status()
+}
+
+func (pw *longPollWriter) Size() int {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	return pw.ResponseWriter.Size()
+}
+
+func (pw *longPollWriter) keepalive(data []byte) {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	if pw.body || !pw.ResponseWriter.Written() {
+		return
+	}
+	pw.ResponseWriter.Write(data)
+	pw.ResponseWriter.Flush()
+}
@@ -0,0 +1,68 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func serveLongPoll(t *testing.T, lp *LongPoll, handler http.HandlerFunc) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	n.Use(lp)
+	n.UseHandlerFunc(handler)
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/poll", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestLongPollKeepaliveAndTimeout(t *testing.T) {
+	start := time.Now()
+	recorder := serveLongPoll(t, NewLongPoll(50*time.Millisecond, 10*time.Millisecond), func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(http.StatusOK)
+		<-r.Context().Done()
+	})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the request to end on timeout, took %v", elapsed)
+	}
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, recorder.Header().Get("Content-Type"), "application/json")
+	expect(t, recorder.Flushed, true)
+	body := recorder.Body.String()
+	if len(body) < 2 || strings.Trim(body, "\n") != "" {
+		t.Errorf("Expected several keepalives, got %q", body)
+	}
+}
+
+func TestLongPollEvent(t *testing.T) {
+	recorder := serveLongPoll(t, NewLongPoll(time.Second, 5*time.Millisecond), func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+		select {
+		case <-time.After(30 * time.Millisecond):
+			rw.Write([]byte(`{"event":1}`))
+		case <-r.Context().Done():
+		}
+	})
+	expect(t, recorder.Code, http.StatusOK)
+	body := recorder.Body.String()
+	expect(t, strings.HasSuffix(body, "\n"+`{"event":1}`), true)
+}
+
+func TestLongPollTimeoutStatus(t *testing.T) {
+	recorder := serveLongPoll(t, NewLongPoll(10*time.Millisecond, time.Millisecond), func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("X-Poll", "timeout")
+		<-r.Context().Done()
+	})
+	expect(t, recorder.Code, http.StatusNoContent)
+	expect(t, recorder.Header().Get("X-Poll"), "timeout")
+	// No keepalives before the handler commits the response.
+	expect(t, recorder.Body.Len(), 0)
+}
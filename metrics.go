@@ -0,0 +1,143 @@
+package negroni
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultMetricsBuckets are the upper bounds, in seconds, of the latency histogram buckets
+// used when a Metrics has no Buckets of its own.
+var DefaultMetricsBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Metrics is a middleware handler that counts requests by method and status and records
+// their latency in a histogram by method. Methods other than the standard ones are counted as
+// OTHER, so clients cannot create an unbounded number of series. WriteTo renders the data in
+// the Prometheus text exposition format, and Handler serves it, e.g. from /metrics.
+type Metrics struct {
+	// Buckets are the sorted upper bounds, in seconds, of the latency histogram buckets. It
+	// must not be changed once the Metrics has served a request.
+	Buckets []float64
+
+	mu         sync.Mutex
+	requests   map[metricsKey]uint64
+	histograms map[string]*histogram
+}
+
+type metricsKey struct {
+	method string
+	status int
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative; the last one is +Inf
+	sum    float64
+	count  uint64
+}
+
+// standardMethods are the request methods Metrics records under their own name.
+var standardMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "CONNECT", "OPTIONS", "TRACE"}
+
+// NewMetrics returns a new instance of Metrics using DefaultMetricsBuckets
+func NewMetrics() *Metrics {
+	return &Metrics{Buckets: DefaultMetricsBuckets}
+}
+
+func (m *Metrics) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	start := time.Now()
+
+	next(rw, r)
+
+	m.observe(r.Method, finalStatus(rw.(ResponseWriter)), time.Since(start))
+}
+
+func (m *Metrics) observe(method string, status int, d time.Duration) {
+	seconds := d.Seconds()
+	if !containsString(standardMethods, method) {
+		method = "OTHER"
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.requests == nil {
+		m.requests = make(map[metricsKey]uint64)
+		m.histograms = make(map[string]*histogram)
+	}
+	m.requests[metricsKey{method, status}]++
+
+	h, ok := m.histograms[method]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.Buckets)+1)}
+		m.histograms[method] = h
+	}
+	i := sort.SearchFloat64s(m.Buckets, seconds)
+	h.counts[i]++
+	h.sum += seconds
+	h.count++
+}
+
+// WriteTo writes the request counter and latency histogram to w in the Prometheus text
+// exposition format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+
+	m.mu.Lock()
+	keys := make([]metricsKey, 0, len(m.requests))
+	for k := range m.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].status < keys[j].status
+	})
+	buf.WriteString("# HELP http_requests_total Total number of HTTP requests by method and status.\n")
+	buf.WriteString("# TYPE http_requests_total counter\n")
+	for _, k := range keys {
+		fmt.Fprintf(&buf, "http_requests_total{method=%s,status=\"%d\"} %d\n",
+			strconv.Quote(k.method), k.status, m.requests[k])
+	}
+
+	methods := make([]string, 0, len(m.histograms))
+	for method := range m.histograms {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	buf.WriteString("# HELP http_request_duration_seconds Latency of HTTP requests by method.\n")
+	buf.WriteString("# TYPE http_request_duration_seconds histogram\n")
+	for _, method := range methods {
+		h := m.histograms[method]
+		label := strconv.Quote(method)
+		var cumulative uint64
+		for i, count := range h.counts {
+			cumulative += count
+			le := "+Inf"
+			if i < len(m.Buckets) {
+				le = strconv.FormatFloat(m.Buckets[i], 'g', -1, 64)
+			}
+			fmt.Fprintf(&buf, "http_request_duration_seconds_bucket{method=%s,le=\"%s\"} %d\n", label, le, cumulative)
+		}
+		fmt.Fprintf(&buf, "http_request_duration_seconds_sum{method=%s} %s\n", label,
+			strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(&buf, "http_request_duration_seconds_count{method=%s} %d\n", label, h.count)
+	}
+	m.mu.Unlock()
+
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+// Handler returns an http.Handler serving the metrics in the Prometheus text exposition
+// format.
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.WriteTo(rw)
+	})
+}
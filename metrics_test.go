@@ -0,0 +1,106 @@
+package negroni
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics()
+
+	n := New()
+	n.Use(m)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	var wg sync.WaitGroup
+	for _, path := range []string{"/", "/", "/missing"} {
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			req, err := http.NewRequest("GET", "http://localhost:3000"+path, nil)
+			if err != nil {
+				t.Error(err)
+			}
+			n.ServeHTTP(httptest.NewRecorder(), req)
+		}(path)
+	}
+	wg.Wait()
+	req, err := http.NewRequest("POST", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(httptest.NewRecorder(), req)
+
+	var buf bytes.Buffer
+	written, err := m.WriteTo(&buf)
+	expect(t, err, nil)
+	expect(t, written, int64(buf.Len()))
+
+	out := buf.String()
+	for _, line := range []string{
+		"# TYPE http_requests_total counter\n",
+		`http_requests_total{method="GET",status="200"} 2` + "\n",
+		`http_requests_total{method="GET",status="404"} 1` + "\n",
+		`http_requests_total{method="POST",status="200"} 1` + "\n",
+		"# TYPE http_request_duration_seconds histogram\n",
+		`http_request_duration_seconds_bucket{method="GET",le="+Inf"} 3` + "\n",
+		`http_request_duration_seconds_count{method="GET"} 3` + "\n",
+		`http_request_duration_seconds_count{method="POST"} 1` + "\n",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("Expected %q in output:\n%s", line, out)
+		}
+	}
+}
+
+func TestMetricsBuckets(t *testing.T) {
+	m := NewMetrics()
+	m.Buckets = []float64{0.1, 1}
+	m.observe("GET", 200, 50*time.Millisecond)
+	m.observe("GET", 200, 500*time.Millisecond)
+	m.observe("GET", 200, 5*time.Second)
+
+	recorder := httptest.NewRecorder()
+	m.Handler().ServeHTTP(recorder, (*http.Request)(nil))
+	expect(t, strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/plain"), true)
+	out := recorder.Body.String()
+	for _, line := range []string{
+		`http_request_duration_seconds_bucket{method="GET",le="0.1"} 1`,
+		`http_request_duration_seconds_bucket{method="GET",le="1"} 2`,
+		`http_request_duration_seconds_bucket{method="GET",le="+Inf"} 3`,
+		`http_request_duration_seconds_sum{method="GET"} 5.55`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("Expected %q in output:\n%s", line, out)
+		}
+	}
+}
+
+func TestMetricsNonStandardMethods(t *testing.T) {
+	m := NewMetrics()
+
+	n := New()
+	n.Use(m)
+	for _, method := range []string{"PROPFIND", "BREW", "get"} {
+		req, err := http.NewRequest(method, "http://localhost:3000/", nil)
+		if err != nil {
+			t.Error(err)
+		}
+		n.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	var buf bytes.Buffer
+	m.WriteTo(&buf)
+	out := buf.String()
+	expect(t, strings.Contains(out, `http_requests_total{method="OTHER",status="200"} 3`+"\n"), true)
+	expect(t, strings.Contains(out, "PROPFIND"), false)
+}
@@ -0,0 +1,158 @@
+package negroni
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+)
+
+// MultipartLimit is a middleware handler that checks multipart/form-data bodies against part
+// limits as they stream in, before the rest of the chain sees them. Forms with too many parts,
+// a part that is too large or a body over MaxBytes are rejected with 413 as soon as the limit
+// is crossed, without reading further; malformed forms get 400. Forms within the limits are
+// parsed with a fixed memory limit, so handlers find them in r.MultipartForm, and their own
+// calls to r.ParseMultipartForm return them as is.
+type MultipartLimit struct {
+	// MaxMemory is the number of bytes kept in memory; the rest is stored in temporary files.
+	MaxMemory int64
+	// MaxParts is the maximum number of parts, values and files, in a form.
+	MaxParts int
+	// MaxPartSize is the maximum size in bytes of a single part.
+	MaxPartSize int64
+	// MaxBytes is the maximum size in bytes of the whole body.
+	MaxBytes int64
+}
+
+// NewMultipartLimit returns a new instance of MultipartLimit keeping 32MB in memory and
+// allowing bodies of up to 64MB with 100 parts of up to 10MB each
+func NewMultipartLimit() *MultipartLimit {
+	return &MultipartLimit{
+		MaxMemory:   32 << 20,
+		MaxParts:    100,
+		MaxPartSize: 10 << 20,
+		MaxBytes:    64 << 20,
+	}
+}
+
+// errMultipartLimit reports a form crossing one of the MultipartLimit limits.
+var errMultipartLimit = errors.New("negroni: multipart form over limit")
+
+func (m *MultipartLimit) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" {
+		next(rw, r)
+		return
+	}
+	if r.Body == nil || params["boundary"] == "" {
+		http.Error(rw, "invalid multipart form", http.StatusBadRequest)
+		return
+	}
+
+	// The body is checked part by part while a copy is spooled, so the form can be parsed
+	// for the handler once it is known to be within the limits.
+	sp := &spool{max: m.MaxMemory}
+	defer sp.close()
+	body := http.MaxBytesReader(rw, r.Body, m.MaxBytes)
+	err = m.check(multipart.NewReader(io.TeeReader(body, sp), params["boundary"]))
+	switch {
+	case sp.err != nil:
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	case err == errMultipartLimit || isMaxBytesError(err):
+		http.Error(rw, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	case err != nil:
+		http.Error(rw, "invalid multipart form", http.StatusBadRequest)
+		return
+	}
+
+	r.Body = ioutil.NopCloser(sp.reader())
+	if err := r.ParseMultipartForm(m.MaxMemory); err != nil {
+		http.Error(rw, "invalid multipart form", http.StatusBadRequest)
+		return
+	}
+	sp.close()
+
+	next(rw, r)
+}
+
+// check reads the parts of mr, failing with errMultipartLimit as soon as there are more than
+// MaxParts or one is larger than MaxPartSize.
+func (m *MultipartLimit) check(mr *multipart.Reader) error {
+	for parts := 1; ; parts++ {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if parts > m.MaxParts {
+			return errMultipartLimit
+		}
+		n, err := io.Copy(ioutil.Discard, io.LimitReader(p, m.MaxPartSize+1))
+		if err != nil {
+			return err
+		}
+		if n > m.MaxPartSize {
+			return errMultipartLimit
+		}
+	}
+}
+
+func isMaxBytesError(err error) bool {
+	var maxBytes *http.MaxBytesError
+	return errors.As(err, &maxBytes)
+}
+
+// spool keeps what is written to it in memory up to max bytes and moves it to a temporary
+// file beyond that.
+type spool struct {
+	max  int64
+	buf  bytes.Buffer
+	file *os.File
+	err  error
+}
+
+func (s *spool) Write(p []byte) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	if s.file == nil && int64(s.buf.Len()+len(p)) > s.max {
+		if s.file, s.err = ioutil.TempFile("", "negroni-multipart-"); s.err != nil {
+			return 0, s.err
+		}
+		if _, s.err = s.file.Write(s.buf.Bytes()); s.err != nil {
+			return 0, s.err
+		}
+		s.buf.Reset()
+	}
+	if s.file != nil {
+		var n int
+		n, s.err = s.file.Write(p)
+		return n, s.err
+	}
+	return s.buf.Write(p)
+}
+
+// reader returns a reader over everything written so far.
+func (s *spool) reader() io.Reader {
+	if s.file != nil {
+		s.file.Seek(0, io.SeekStart)
+		return s.file
+	}
+	return &s.buf
+}
+
+func (s *spool) close() {
+	if s.file != nil {
+		s.file.Close()
+		os.Remove(s.file.Name())
+		s.file = nil
+	}
+}
@@ -0,0 +1,148 @@
+package negroni
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func multipartRequest(t *testing.T, files map[string]string) *http.Request {
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	for name, content := range files {
+		w, err := mw.CreateFormFile(name, name+".txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	mw.Close()
+
+	req, err := http.NewRequest("POST", "http://localhost:3000/upload", body)
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestMultipartLimit(t *testing.T) {
+	limit := NewMultipartLimit()
+	limit.MaxParts = 2
+	limit.MaxPartSize = 16
+
+	n := New()
+	n.Use(limit)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		f, _, err := r.FormFile("a")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer f.Close()
+		rw.Write([]byte("uploaded"))
+	})
+
+	recorder := httptest.NewRecorder()
+	n.ServeHTTP(recorder, multipartRequest(t, map[string]string{"a": "hello", "b": "world"}))
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, recorder.Body.String(), "uploaded")
+
+	recorder = httptest.NewRecorder()
+	n.ServeHTTP(recorder, multipartRequest(t, map[string]string{"a": "1", "b": "2", "c": "3"}))
+	expect(t, recorder.Code, http.StatusRequestEntityTooLarge)
+
+	recorder = httptest.NewRecorder()
+	n.ServeHTTP(recorder, multipartRequest(t, map[string]string{"a": strings.Repeat("x", 17)}))
+	expect(t, recorder.Code, http.StatusRequestEntityTooLarge)
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func TestMultipartLimitStopsEarly(t *testing.T) {
+	limit := NewMultipartLimit()
+	limit.MaxPartSize = 16
+
+	n := New()
+	n.Use(limit)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		t.Error("handler should not run")
+	})
+
+	req := multipartRequest(t, map[string]string{"a": strings.Repeat("x", 1<<20)})
+	body := &countingReader{r: req.Body}
+	req.Body = ioutil.NopCloser(body)
+
+	recorder := httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusRequestEntityTooLarge)
+	if body.n >= 1<<20 {
+		t.Errorf("Expected reading to stop early, read %d bytes", body.n)
+	}
+}
+
+func TestMultipartLimitMaxBytes(t *testing.T) {
+	limit := NewMultipartLimit()
+	limit.MaxBytes = 1024
+
+	n := New()
+	n.Use(limit)
+
+	recorder := httptest.NewRecorder()
+	n.ServeHTTP(recorder, multipartRequest(t, map[string]string{"a": strings.Repeat("x", 600), "b": strings.Repeat("y", 600)}))
+	expect(t, recorder.Code, http.StatusRequestEntityTooLarge)
+}
+
+func TestMultipartLimitSpoolsToDisk(t *testing.T) {
+	limit := NewMultipartLimit()
+	limit.MaxMemory = 8
+
+	n := New()
+	n.Use(limit)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		f, _, err := r.FormFile("a")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer f.Close()
+		b, _ := ioutil.ReadAll(f)
+		rw.Write(b)
+	})
+
+	recorder := httptest.NewRecorder()
+	n.ServeHTTP(recorder, multipartRequest(t, map[string]string{"a": "hello, world"}))
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, recorder.Body.String(), "hello, world")
+}
+
+func TestMultipartLimitInvalidForm(t *testing.T) {
+	n := New()
+	n.Use(NewMultipartLimit())
+
+	req, err := http.NewRequest("POST", "http://localhost:3000/upload", strings.NewReader("--xyz\r\nbroken"))
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("Content-Type", "multipart/form-data; boundary=xyz")
+
+	recorder := httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusBadRequest)
+	expect(t, recorder.Body.String(), "invalid multipart form\n")
+}
@@ -0,0 +1,87 @@
+package negroni
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type acceptRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// parseAccept parses an Accept header into its media ranges and their quality values.
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		mediaRange := strings.ToLower(strings.TrimSpace(fields[0]))
+		slash := strings.Index(mediaRange, "/")
+		if slash < 0 {
+			if mediaRange != "*" {
+				continue
+			}
+			mediaRange, slash = "*/*", 1
+		}
+
+		ar := acceptRange{typ: mediaRange[:slash], subtype: mediaRange[slash+1:], q: 1}
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if len(param) > 2 && (param[0] == 'q' || param[0] == 'Q') && param[1] == '=' {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil && q >= 0 && q <= 1 {
+					ar.q = q
+				}
+			}
+		}
+		ranges = append(ranges, ar)
+	}
+	return ranges
+}
+
+// NegotiateContentType returns the offer that best matches the request's Accept header, or ""
+// if none of the offers are acceptable. Quality values decide first, then how specifically the
+// header matched the offer (text/html over text/* over */*), then the order of offers. When the
+// request has no Accept header the first offer is returned.
+func NegotiateContentType(r *http.Request, offers ...string) string {
+	header := r.Header.Get("Accept")
+	if header == "" {
+		if len(offers) == 0 {
+			return ""
+		}
+		return offers[0]
+	}
+	ranges := parseAccept(header)
+
+	best, bestQ, bestSpecificity := "", 0.0, -1
+	for _, offer := range offers {
+		slash := strings.Index(offer, "/")
+		if slash < 0 {
+			continue
+		}
+		typ, subtype := strings.ToLower(offer[:slash]), strings.ToLower(offer[slash+1:])
+
+		q, specificity := 0.0, -1
+		for _, ar := range ranges {
+			var s int
+			switch {
+			case ar.typ == typ && ar.subtype == subtype:
+				s = 2
+			case ar.typ == typ && ar.subtype == "*":
+				s = 1
+			case ar.typ == "*" && ar.subtype == "*":
+				s = 0
+			default:
+				continue
+			}
+			if s > specificity {
+				q, specificity = ar.q, s
+			}
+		}
+
+		if q > bestQ || (q == bestQ && q > 0 && specificity > bestSpecificity) {
+			best, bestQ, bestSpecificity = offer, q, specificity
+		}
+	}
+	return best
+}
@@ -0,0 +1,39 @@
+package negroni
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestNegotiateContentType(t *testing.T) {
+	cases := []struct {
+		accept string
+		offers []string
+		want   string
+	}{
+		{"", []string{"application/json", "text/html"}, "application/json"},
+		{"text/html", []string{"application/json", "text/html"}, "text/html"},
+		{"text/html;q=0.5, application/json", []string{"text/html", "application/json"}, "application/json"},
+		{"application/json;q=0.2, text/html;q=0.9", []string{"application/json", "text/html"}, "text/html"},
+		{"text/*", []string{"application/json", "text/plain"}, "text/plain"},
+		{"*/*", []string{"application/json", "text/html"}, "application/json"},
+		{"*/*;q=0.1, text/html", []string{"application/json", "text/html"}, "text/html"},
+		{"text/*;q=0.5, text/plain;q=0", []string{"text/plain", "text/csv"}, "text/csv"},
+		{"image/png", []string{"application/json", "text/html"}, ""},
+		{"application/json;q=0", []string{"application/json"}, ""},
+		{"TEXT/HTML", []string{"text/html"}, "text/html"},
+	}
+
+	for _, c := range cases {
+		req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+		if err != nil {
+			t.Error(err)
+		}
+		if c.accept != "" {
+			req.Header.Set("Accept", c.accept)
+		}
+		if got := NegotiateContentType(req, c.offers...); got != c.want {
+			t.Errorf("Accept %q with offers %v: expected %q, got %q", c.accept, c.offers, c.want, got)
+		}
+	}
+}
@@ -1,9 +1,14 @@
 package negroni
 
 import (
+	"context"
+	"crypto/tls"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"sync"
+	"time"
 )
 
 // Handler handler is an interface that objects can implement to be registered to serve as middleware
@@ -27,20 +32,51 @@ func (h HandlerFunc) ServeHTTP(rw http.ResponseWriter, r *http.Request, next htt
 type middleware struct {
 	handler Handler
 	next    *middleware
+	index   int
+	onPanic func(*HandlerPanic) bool
+	trace   bool
 }
 
 func (m middleware) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if m.trace {
+		m.recordTrace(r)
+	}
+	if m.onPanic != nil {
+		m.serveIsolated(rw, r)
+		return
+	}
 	m.handler.ServeHTTP(rw, r, m.next.ServeHTTP)
 }
 
 // Wrap converts a http.Handler into a negroni.Handler so it can be used as a Negroni
 // middleware. The next http.HandlerFunc is automatically called after the Handler
-// is executed.
+// is executed, unless the Handler wrote a response.
 func Wrap(handler http.Handler) Handler {
-	return HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
-		handler.ServeHTTP(rw, r)
-		next(rw, r)
-	})
+	return wrapped{handler: handler}
+}
+
+// WrapAlways is like Wrap, but the next http.HandlerFunc is called even if the Handler
+// wrote a response.
+func WrapAlways(handler http.Handler) Handler {
+	return wrapped{handler: handler, always: true}
+}
+
+type wrapped struct {
+	handler http.Handler
+	always  bool
+}
+
+func (w wrapped) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	w.handler.ServeHTTP(rw, r)
+	if res, ok := rw.(ResponseWriter); ok && res.Written() && !w.always {
+		return
+	}
+	next(rw, r)
+}
+
+// Role identifies wrapped http.Handlers as application handlers to Validate.
+func (w wrapped) Role() HandlerRole {
+	return RoleApp
 }
 
 // Negroni is a stack of Middleware Handlers that can be invoked as an http.Handler.
@@ -49,8 +85,21 @@ func Wrap(handler http.Handler) Handler {
 type Negroni struct {
 	middleware middleware
 	handlers   []Handler
+	fallbacks  []Handler
+	observer   ObserverFunc
+	onPanic    func(*HandlerPanic) bool
+	root       context.Context
+	trace      bool
+	serverMu   sync.Mutex
+	server     *http.Server
+	// tail is the void middleware at the end of the chain, where Use appends.
+	tail *middleware
 }
 
+// ObserverFunc is called by Negroni after the middleware chain has handled a request, with the
+// final response status and the time the chain took.
+type ObserverFunc func(r *http.Request, status int, duration time.Duration)
+
 // New returns a new Negroni instance with no middleware preconfigured.
 func New(handlers ...Handler) *Negroni {
 	return &Negroni{
@@ -69,14 +118,94 @@ func Classic() *Negroni {
 	return New(NewRecovery(), NewLogger(), NewStatic(http.Dir("public")))
 }
 
+// ServeHTTP runs the middleware stack for r. The request's context is handed to the chain as
+// is, so deadlines, values and cancellation set by the server or outer handlers reach every
+// handler.
 func (n *Negroni) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
-	n.middleware.ServeHTTP(NewResponseWriter(rw), r)
+	start := time.Now()
+	if n.root != nil && r != nil {
+		r = r.WithContext(rootedContext{r.Context(), n.root})
+	}
+	if n.trace && r != nil {
+		r = r.WithContext(context.WithValue(r.Context(), traceKey{}, &trace{}))
+	}
+	res := NewResponseWriter(rw)
+	n.middleware.ServeHTTP(res, r)
+
+	for _, h := range n.fallbacks {
+		if res.Written() {
+			break
+		}
+		h.ServeHTTP(res, r, func(http.ResponseWriter, *http.Request) {})
+	}
+
+	if n.observer == nil {
+		return
+	}
+	n.observer(r, finalStatus(res), time.Since(start))
+}
+
+// WithRootContext sets a context, for example one carrying a database pool or a tracer, whose
+// values are visible to every request alongside those of the request's own context. Values
+// set on the request context win when both carry the same key. Deadlines and cancellation
+// come from the request context only: canceling ctx does not cancel requests in flight.
+func (n *Negroni) WithRootContext(ctx context.Context) *Negroni {
+	n.root = ctx
+	return n
+}
+
+// Observe sets a function that is called after every request with its final status and
+// duration. It is a lightweight alternative to writing a middleware for basic metrics.
+func (n *Negroni) Observe(fn ObserverFunc) {
+	n.observer = fn
 }
 
 // Use adds a Handler onto the middleware stack. Handlers are invoked in the order they are added to a Negroni.
 func (n *Negroni) Use(handler Handler) {
-	n.handlers = append(n.handlers, handler)
-	n.middleware = build(n.handlers)
+	n.UseAll(handler)
+}
+
+// UseAll adds several Handlers onto the middleware stack at once, in the order given. The
+// handlers are linked onto the end of the existing chain, so the cost of adding them does not
+// grow with the size of the stack.
+func (n *Negroni) UseAll(handlers ...Handler) {
+	if len(handlers) == 0 {
+		return
+	}
+	index := len(n.handlers)
+	n.handlers = append(n.handlers, handlers...)
+
+	tail := n.findTail()
+	*tail = build(handlers)
+	if n.onPanic != nil {
+		isolate(tail, index, len(handlers), n.onPanic)
+	}
+	if n.trace {
+		traceNodes(tail)
+	}
+	n.tail = n.findTail()
+}
+
+// With returns a new Negroni whose stack is the receiver's handlers followed by handlers,
+// leaving the receiver untouched. Fallbacks, the observer, panic isolation and the root
+// context are carried over. The two stacks are independent: later calls to Use on either
+// do not affect the other. It suits a shared base stack mounted with different handlers
+// per route group.
+func (n *Negroni) With(handlers ...Handler) *Negroni {
+	all := make([]Handler, 0, len(n.handlers)+len(handlers))
+	all = append(all, n.handlers...)
+	all = append(all, handlers...)
+
+	clone := &Negroni{
+		handlers:  all,
+		fallbacks: append([]Handler(nil), n.fallbacks...),
+		observer:  n.observer,
+		onPanic:   n.onPanic,
+		root:      n.root,
+		trace:     n.trace,
+	}
+	clone.rebuild()
+	return clone
 }
 
 // UseFunc adds a Negroni-style handler function onto the middleware stack.
@@ -84,6 +213,14 @@ func (n *Negroni) UseFunc(handlerFunc func(rw http.ResponseWriter, r *http.Reque
 	n.Use(HandlerFunc(handlerFunc))
 }
 
+// UseFallback adds a Handler that is invoked only if the middleware stack and any earlier
+// fallbacks completed without writing a response, e.g. to try a proxy and then render a 404
+// page. Fallbacks run in the order they are added, after the stack has returned, so they are
+// not covered by middleware such as Recovery. The next handler passed to them does nothing.
+func (n *Negroni) UseFallback(handler Handler) {
+	n.fallbacks = append(n.fallbacks, handler)
+}
+
 // UseHandler adds a http.Handler onto the middleware stack. Handlers are invoked in the order they are added to a Negroni.
 func (n *Negroni) UseHandler(handler http.Handler) {
 	n.Use(Wrap(handler))
@@ -96,10 +233,114 @@ func (n *Negroni) UseHandlerFunc(handlerFunc func(rw http.ResponseWriter, r *htt
 
 // Run is a convenience function that runs the negroni stack as an HTTP
 // server. The addr string takes the same format as http.ListenAndServe.
+// Run exits the process if the server fails, use Serve to handle the error instead.
 func (n *Negroni) Run(addr string) {
+	l := log.New(os.Stdout, "[negroni] ", 0)
+	l.Fatal(n.Serve(addr))
+}
+
+// Serve runs the negroni stack as an HTTP server like Run, but returns the error that
+// stopped it, such as a failure to bind addr, instead of exiting the process.
+func (n *Negroni) Serve(addr string) error {
 	l := log.New(os.Stdout, "[negroni] ", 0)
 	l.Printf("listening on %s", addr)
-	l.Fatal(http.ListenAndServe(addr, n))
+	return n.RunServer(&http.Server{Addr: addr})
+}
+
+// RunServer runs the negroni stack as the handler of srv and returns the error from
+// srv.ListenAndServe. After Shutdown it returns http.ErrServerClosed.
+func (n *Negroni) RunServer(srv *http.Server) error {
+	n.setServer(srv)
+	return srv.ListenAndServe()
+}
+
+// RunTLS runs the negroni stack as an HTTPS server, like http.ListenAndServeTLS, and returns
+// the error that stopped it.
+func (n *Negroni) RunTLS(addr, certFile, keyFile string) error {
+	return n.runTLS(&http.Server{Addr: addr}, certFile, keyFile)
+}
+
+// RunTLSConfig runs the negroni stack as an HTTPS server using cfg, which must provide the
+// certificates and may pin cipher suites or ALPN protocols, and returns the error that
+// stopped it.
+func (n *Negroni) RunTLSConfig(addr string, cfg *tls.Config) error {
+	return n.runTLS(&http.Server{Addr: addr, TLSConfig: cfg}, "", "")
+}
+
+func (n *Negroni) runTLS(srv *http.Server, certFile, keyFile string) error {
+	l := log.New(os.Stdout, "[negroni] ", 0)
+	l.Printf("listening on %s", srv.Addr)
+	n.setServer(srv)
+	return srv.ListenAndServeTLS(certFile, keyFile)
+}
+
+func (n *Negroni) setServer(srv *http.Server) {
+	srv.Handler = n
+	n.serverMu.Lock()
+	n.server = srv
+	n.serverMu.Unlock()
+}
+
+// Shutdown gracefully shuts down the server started by RunServer, RunTLS or RunTLSConfig, waiting for in-flight
+// requests to complete until ctx is done. It does nothing if no server is running.
+func (n *Negroni) Shutdown(ctx context.Context) error {
+	n.serverMu.Lock()
+	srv := n.server
+	n.serverMu.Unlock()
+	if srv == nil {
+		return nil
+	}
+	return srv.Shutdown(ctx)
+}
+
+// Insert adds handler to the stack at index, a position in Handlers(), shifting the handler
+// there and those after it back by one. An index equal to the number of handlers appends
+// handler to the end. It returns an error if index is out of range.
+func (n *Negroni) Insert(index int, handler Handler) error {
+	if index != len(n.handlers) {
+		if err := n.checkIndex(index); err != nil {
+			return err
+		}
+	}
+	handlers := make([]Handler, 0, len(n.handlers)+1)
+	handlers = append(handlers, n.handlers[:index]...)
+	handlers = append(handlers, handler)
+	n.handlers = append(handlers, n.handlers[index:]...)
+	n.rebuild()
+	return nil
+}
+
+// Replace swaps the handler at index, a position in Handlers(), for handler. It returns an
+// error if index is out of range.
+func (n *Negroni) Replace(index int, handler Handler) error {
+	if err := n.checkIndex(index); err != nil {
+		return err
+	}
+	handlers := append([]Handler(nil), n.handlers...)
+	handlers[index] = handler
+	n.handlers = handlers
+	n.rebuild()
+	return nil
+}
+
+// Remove removes the handler at index, a position in Handlers(), from the stack. It returns
+// an error if index is out of range.
+func (n *Negroni) Remove(index int) error {
+	if err := n.checkIndex(index); err != nil {
+		return err
+	}
+	handlers := make([]Handler, 0, len(n.handlers)-1)
+	handlers = append(handlers, n.handlers[:index]...)
+	n.handlers = append(handlers, n.handlers[index+1:]...)
+	n.rebuild()
+	return nil
+}
+
+func (n *Negroni) checkIndex(index int) error {
+	if index < 0 || index >= len(n.handlers) {
+		return fmt.Errorf("negroni: handler index %d out of range for %d handlers", index, len(n.handlers))
+	}
+	return nil
 }
 
 // Returns a list of all the handlers in the current Negroni middleware chain.
@@ -107,6 +348,29 @@ func (n *Negroni) Handlers() []Handler {
 	return n.handlers
 }
 
+func (n *Negroni) rebuild() {
+	n.middleware = build(n.handlers)
+	n.tail = nil
+	if n.onPanic != nil {
+		isolate(&n.middleware, 0, len(n.handlers), n.onPanic)
+	}
+	if n.trace {
+		traceNodes(&n.middleware)
+	}
+}
+
+// findTail returns the void middleware that ends the chain.
+func (n *Negroni) findTail() *middleware {
+	m := n.tail
+	if m == nil {
+		m = &n.middleware
+	}
+	for m.next != nil && m.next.next != nil {
+		m = m.next
+	}
+	return m
+}
+
 func build(handlers []Handler) middleware {
 	var next middleware
 
@@ -118,12 +382,12 @@ func build(handlers []Handler) middleware {
 		next = voidMiddleware()
 	}
 
-	return middleware{handlers[0], &next}
+	return middleware{handler: handlers[0], next: &next}
 }
 
 func voidMiddleware() middleware {
 	return middleware{
-		HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {}),
-		&middleware{},
+		handler: HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {}),
+		next:    &middleware{},
 	}
 }
@@ -1,10 +1,14 @@
 package negroni
 
 import (
+	"context"
+	"crypto/tls"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 )
 
 /* Test Helpers */
@@ -25,6 +29,67 @@ func TestNegroniRun(t *testing.T) {
 	go New().Run(":3000")
 }
 
+func TestNegroniServeBindError(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	err = New().Serve(l.Addr().String())
+	refute(t, err, nil)
+}
+
+func TestNegroniRunServerShutdown(t *testing.T) {
+	n := New()
+	expect(t, n.Shutdown(context.Background()), nil)
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- n.RunServer(&http.Server{Addr: "127.0.0.1:0"})
+	}()
+
+	for {
+		if err := n.Shutdown(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case err := <-errc:
+			expect(t, err, http.ErrServerClosed)
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestNegroniRunTLSMissingCertificate(t *testing.T) {
+	err := New().RunTLS("127.0.0.1:0", "missing-cert.pem", "missing-key.pem")
+	refute(t, err, nil)
+}
+
+func TestNegroniRunTLSConfigShutdown(t *testing.T) {
+	ts := httptest.NewTLSServer(http.NotFoundHandler())
+	ts.Close()
+
+	n := New()
+	errc := make(chan error, 1)
+	go func() {
+		errc <- n.RunTLSConfig("127.0.0.1:0", &tls.Config{Certificates: ts.TLS.Certificates})
+	}()
+
+	for {
+		if err := n.Shutdown(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case err := <-errc:
+			expect(t, err, http.ErrServerClosed)
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
 func TestNegroniServeHTTP(t *testing.T) {
 	result := ""
 	response := httptest.NewRecorder()
@@ -51,7 +116,7 @@ func TestNegroniServeHTTP(t *testing.T) {
 	expect(t, response.Code, http.StatusBadRequest)
 }
 
-// Ensures that a Negroni middleware chain 
+// Ensures that a Negroni middleware chain
 // can correctly return all of its handlers.
 func TestHandlers(t *testing.T) {
 	response := httptest.NewRecorder()
@@ -63,7 +128,7 @@ func TestHandlers(t *testing.T) {
 		rw.WriteHeader(http.StatusOK)
 	}))
 
-	// Expects the length of handlers to be exactly 1 
+	// Expects the length of handlers to be exactly 1
 	// after adding exactly one handler to the middleware chain
 	handlers = n.Handlers()
 	expect(t, 1, len(handlers))
@@ -72,4 +137,290 @@ func TestHandlers(t *testing.T) {
 	// exactly the same as the one that was registered earlier
 	handlers[0].ServeHTTP(response, (*http.Request)(nil), nil)
 	expect(t, response.Code, http.StatusOK)
-}
\ No newline at end of file
+}
+
+type testContextKey struct{}
+
+func TestNegroniPropagatesRequestContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), testContextKey{}, "outer"))
+	cancel()
+
+	n := New()
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		expect(t, r.Context().Value(testContextKey{}), "outer")
+		select {
+		case <-r.Context().Done():
+		default:
+			t.Error("expected the request context to be canceled")
+		}
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(httptest.NewRecorder(), req.WithContext(ctx))
+}
+
+type testRootKey struct{}
+
+func TestNegroniWithRootContext(t *testing.T) {
+	root, cancelRoot := context.WithCancel(context.WithValue(context.Background(), testRootKey{}, "pool"))
+	reqCtx := context.WithValue(context.Background(), testContextKey{}, "outer")
+
+	n := New().WithRootContext(root)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		expect(t, r.Context().Value(testRootKey{}), "pool")
+		expect(t, r.Context().Value(testContextKey{}), "outer")
+		expect(t, r.Context().Err(), nil)
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	cancelRoot()
+	n.ServeHTTP(httptest.NewRecorder(), req.WithContext(reqCtx))
+}
+
+func TestNegroniUseAll(t *testing.T) {
+	result := ""
+	step := func(s string) Handler {
+		return HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			result += s
+			next(rw, r)
+		})
+	}
+
+	n := New(step("a"))
+	n.UseAll(step("b"), step("c"))
+	n.Use(step("d"))
+	n.UseAll()
+	n.UseAll(step("e"), step("f"))
+
+	n.ServeHTTP(httptest.NewRecorder(), (*http.Request)(nil))
+	expect(t, result, "abcdef")
+	expect(t, len(n.Handlers()), 6)
+
+	var zero Negroni
+	zero.UseAll(step("x"), step("y"))
+	zero.Use(step("z"))
+	result = ""
+	zero.ServeHTTP(httptest.NewRecorder(), (*http.Request)(nil))
+	expect(t, result, "xyz")
+}
+
+func BenchmarkNegroniUse(b *testing.B) {
+	h := HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		next(rw, r)
+	})
+	for i := 0; i < b.N; i++ {
+		n := New()
+		for j := 0; j < 100; j++ {
+			n.Use(h)
+		}
+	}
+}
+
+func TestNegroniWith(t *testing.T) {
+	result := ""
+	step := func(s string) Handler {
+		return HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			result += s
+			next(rw, r)
+		})
+	}
+
+	base := New(step("recovery"), step("+logger"))
+	api := base.With(step("+auth"))
+	admin := base.With(step("+admin"))
+	base.Use(step("+base"))
+	api.Use(step("+api"))
+
+	for n, want := range map[*Negroni]string{
+		base:  "recovery+logger+base",
+		api:   "recovery+logger+auth+api",
+		admin: "recovery+logger+admin",
+	} {
+		result = ""
+		n.ServeHTTP(httptest.NewRecorder(), (*http.Request)(nil))
+		expect(t, result, want)
+	}
+	expect(t, len(base.Handlers()), 3)
+	expect(t, len(api.Handlers()), 4)
+}
+
+func TestWrapSkipsNextWhenWritten(t *testing.T) {
+	nextCalls := 0
+	next := func(rw http.ResponseWriter, r *http.Request) { nextCalls++ }
+	silent := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {})
+
+	Wrap(silent).ServeHTTP(NewResponseWriter(httptest.NewRecorder()), (*http.Request)(nil), next)
+	expect(t, nextCalls, 1)
+
+	Wrap(http.NotFoundHandler()).ServeHTTP(NewResponseWriter(httptest.NewRecorder()), (*http.Request)(nil), next)
+	expect(t, nextCalls, 1)
+
+	WrapAlways(http.NotFoundHandler()).ServeHTTP(NewResponseWriter(httptest.NewRecorder()), (*http.Request)(nil), next)
+	expect(t, nextCalls, 2)
+}
+
+func TestNegroniReplaceRemove(t *testing.T) {
+	result := ""
+	step := func(s string) Handler {
+		return HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			result += s
+			next(rw, r)
+		})
+	}
+	serve := func(n *Negroni) string {
+		result = ""
+		n.ServeHTTP(httptest.NewRecorder(), (*http.Request)(nil))
+		return result
+	}
+
+	empty := New()
+	refute(t, empty.Replace(0, step("a")), nil)
+	refute(t, empty.Remove(0), nil)
+
+	n := New(step("a"), step("b"), step("c"))
+	refute(t, n.Replace(-1, step("x")), nil)
+	refute(t, n.Replace(3, step("x")), nil)
+	refute(t, n.Remove(3), nil)
+
+	expect(t, n.Replace(1, step("B")), nil)
+	expect(t, serve(n), "aBc")
+	expect(t, n.Replace(2, step("C")), nil)
+	expect(t, serve(n), "aBC")
+
+	expect(t, n.Remove(2), nil)
+	expect(t, serve(n), "aB")
+	expect(t, n.Remove(0), nil)
+	expect(t, serve(n), "B")
+	expect(t, len(n.Handlers()), 1)
+	expect(t, n.Remove(0), nil)
+	expect(t, serve(n), "")
+
+	n.Use(step("d"))
+	expect(t, serve(n), "d")
+}
+
+func TestNegroniInsert(t *testing.T) {
+	result := ""
+	step := func(s string) Handler {
+		return HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			result += s
+			next(rw, r)
+		})
+	}
+	serve := func(n *Negroni) string {
+		result = ""
+		n.ServeHTTP(httptest.NewRecorder(), (*http.Request)(nil))
+		return result
+	}
+
+	n := New()
+	refute(t, n.Insert(-1, step("x")), nil)
+	refute(t, n.Insert(1, step("x")), nil)
+	expect(t, n.Insert(0, step("logger")), nil)
+	expect(t, n.Insert(0, step("recovery+")), nil)
+	expect(t, n.Insert(1, step("auth+")), nil)
+	expect(t, n.Insert(3, step("+app")), nil)
+	refute(t, n.Insert(5, step("x")), nil)
+
+	expect(t, serve(n), "recovery+auth+logger+app")
+	expect(t, len(n.Handlers()), 4)
+
+	n.Use(step("+end"))
+	expect(t, serve(n), "recovery+auth+logger+app+end")
+}
+
+func TestNegroniObserve(t *testing.T) {
+	calls := 0
+	var status int
+	var duration time.Duration
+
+	n := New()
+	n.Observe(func(r *http.Request, s int, d time.Duration) {
+		calls++
+		status = s
+		duration = d
+	})
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+		rw.WriteHeader(http.StatusAccepted)
+	})
+
+	n.ServeHTTP(httptest.NewRecorder(), (*http.Request)(nil))
+	expect(t, calls, 1)
+	expect(t, status, http.StatusAccepted)
+	if duration < 5*time.Millisecond {
+		t.Errorf("Expected duration of at least 5ms, got %v", duration)
+	}
+}
+
+func TestNegroniObserveDefaultStatus(t *testing.T) {
+	var status int
+
+	n := New()
+	n.Observe(func(r *http.Request, s int, d time.Duration) {
+		status = s
+	})
+
+	n.ServeHTTP(httptest.NewRecorder(), (*http.Request)(nil))
+	expect(t, status, http.StatusOK)
+}
+
+func TestNegroniFallbacks(t *testing.T) {
+	result := ""
+
+	n := New()
+	n.UseFallback(HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		result += "proxy"
+	}))
+	n.UseFallback(HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		result += "notfound"
+		rw.WriteHeader(http.StatusNotFound)
+	}))
+	n.UseFallback(HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		result += "unreachable"
+	}))
+
+	response := httptest.NewRecorder()
+	n.ServeHTTP(response, (*http.Request)(nil))
+	expect(t, result, "proxynotfound")
+	expect(t, response.Code, http.StatusNotFound)
+}
+
+func TestNegroniFallbacksFirstWrites(t *testing.T) {
+	result := ""
+
+	n := New()
+	n.UseFallback(HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		result += "proxy"
+		rw.WriteHeader(http.StatusBadGateway)
+	}))
+	n.UseFallback(HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		result += "notfound"
+	}))
+
+	response := httptest.NewRecorder()
+	n.ServeHTTP(response, (*http.Request)(nil))
+	expect(t, result, "proxy")
+	expect(t, response.Code, http.StatusBadGateway)
+}
+
+func TestNegroniFallbacksSkippedWhenWritten(t *testing.T) {
+	called := false
+
+	n := New()
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+	n.UseFallback(HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		called = true
+	}))
+
+	n.ServeHTTP(httptest.NewRecorder(), (*http.Request)(nil))
+	expect(t, called, false)
+}
@@ -0,0 +1,112 @@
+package negroni
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// NonceStore keeps track of nonces that have already been used.
+type NonceStore interface {
+	// Add records nonce for ttl and reports whether it was unused. It must be atomic so two
+	// concurrent requests with the same nonce cannot both succeed.
+	Add(nonce string, ttl time.Duration) bool
+	// Remove forgets nonce so that it may be used again.
+	Remove(nonce string)
+}
+
+// MemoryNonceStore is an in-memory NonceStore safe for concurrent use. Expired nonces are
+// ignored when looked up and dropped by a sweep that runs at most once per PruneInterval, so
+// the store holds at most the nonces of one TTL plus one PruneInterval.
+type MemoryNonceStore struct {
+	// PruneInterval is the minimum time between sweeps for expired nonces.
+	PruneInterval time.Duration
+
+	mu        sync.Mutex
+	nonces    map[string]time.Time
+	lastPrune time.Time
+}
+
+// NewMemoryNonceStore returns a new, empty MemoryNonceStore pruning expired nonces once a
+// minute
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{
+		PruneInterval: time.Minute,
+		nonces:        make(map[string]time.Time),
+		lastPrune:     time.Now(),
+	}
+}
+
+func (s *MemoryNonceStore) Add(nonce string, ttl time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.nonces == nil {
+		s.nonces = make(map[string]time.Time)
+	}
+	now := time.Now()
+	if now.Sub(s.lastPrune) >= s.PruneInterval {
+		for n, expires := range s.nonces {
+			if now.After(expires) {
+				delete(s.nonces, n)
+			}
+		}
+		s.lastPrune = now
+	}
+	if expires, ok := s.nonces[nonce]; ok && !now.After(expires) {
+		return false
+	}
+	s.nonces[nonce] = now.Add(ttl)
+	return true
+}
+
+func (s *MemoryNonceStore) Remove(nonce string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.nonces, nonce)
+}
+
+// Nonce is a middleware handler that enforces exactly-once processing. Every request must carry
+// a client generated nonce; a nonce that was already used is rejected with 409 Conflict. The
+// nonce is released again when the request fails with a 4xx or 5xx status or the handler
+// panics, so the client may retry it.
+type Nonce struct {
+	// Header is the request header carrying the nonce.
+	Header string
+	// TTL is how long a used nonce is remembered.
+	TTL time.Duration
+	// Store records used nonces.
+	Store NonceStore
+}
+
+// NewNonce returns a new instance of Nonce backed by store
+func NewNonce(store NonceStore) *Nonce {
+	return &Nonce{
+		Header: "X-Nonce",
+		TTL:    24 * time.Hour,
+		Store:  store,
+	}
+}
+
+func (n *Nonce) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	nonce := r.Header.Get(n.Header)
+	if nonce == "" {
+		http.Error(rw, "missing "+n.Header+" header", http.StatusBadRequest)
+		return
+	}
+	if !n.Store.Add(nonce, n.TTL) {
+		http.Error(rw, "nonce already used", http.StatusConflict)
+		return
+	}
+
+	// The nonce is also released when the handler panics, without recovering the panic.
+	returned := false
+	defer func() {
+		if !returned || rw.(ResponseWriter).Status() >= http.StatusBadRequest {
+			n.Store.Remove(nonce)
+		}
+	}()
+
+	next(rw, r)
+	returned = true
+}
@@ -0,0 +1,133 @@
+package negroni
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNonce(t *testing.T) {
+	calls := 0
+
+	n := New()
+	n.Use(NewNonce(NewMemoryNonceStore()))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		calls++
+		rw.WriteHeader(http.StatusCreated)
+	})
+
+	req, err := http.NewRequest("POST", "http://localhost:3000/payments", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("X-Nonce", "n-1")
+
+	recorder := httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusCreated)
+
+	recorder = httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusConflict)
+	expect(t, calls, 1)
+}
+
+func TestNonceMissing(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	n.Use(NewNonce(NewMemoryNonceStore()))
+
+	req, err := http.NewRequest("POST", "http://localhost:3000/payments", nil)
+	if err != nil {
+		t.Error(err)
+	}
+
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusBadRequest)
+}
+
+func TestNonceReleasedOnFailure(t *testing.T) {
+	status := http.StatusInternalServerError
+
+	n := New()
+	n.Use(NewNonce(NewMemoryNonceStore()))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(status)
+	})
+
+	req, err := http.NewRequest("POST", "http://localhost:3000/payments", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("X-Nonce", "n-1")
+
+	recorder := httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusInternalServerError)
+
+	status = http.StatusOK
+	recorder = httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusOK)
+}
+
+func TestMemoryNonceStoreExpires(t *testing.T) {
+	s := NewMemoryNonceStore()
+	expect(t, s.Add("a", time.Millisecond), true)
+	expect(t, s.Add("a", time.Millisecond), false)
+	time.Sleep(5 * time.Millisecond)
+	expect(t, s.Add("a", time.Millisecond), true)
+}
+
+func TestMemoryNonceStorePrunesPerInterval(t *testing.T) {
+	s := NewMemoryNonceStore()
+	s.PruneInterval = time.Hour
+	expect(t, s.Add("a", time.Millisecond), true)
+	time.Sleep(5 * time.Millisecond)
+	expect(t, s.Add("b", time.Hour), true)
+	expect(t, len(s.nonces), 2)
+
+	s.PruneInterval = time.Millisecond
+	expect(t, s.Add("c", time.Hour), true)
+	expect(t, len(s.nonces), 2)
+	expect(t, s.Add("b", time.Hour), false)
+}
+
+func TestMemoryNonceStoreZeroValue(t *testing.T) {
+	s := &MemoryNonceStore{}
+	expect(t, s.Add("a", time.Hour), true)
+	expect(t, s.Add("a", time.Hour), false)
+}
+
+func TestNonceReleasedOnPanic(t *testing.T) {
+	fail := true
+	rec := NewRecovery()
+	rec.Logger = log.New(ioutil.Discard, "", 0)
+
+	n := New()
+	n.Use(rec)
+	n.Use(NewNonce(NewMemoryNonceStore()))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if fail {
+			panic("boom")
+		}
+	})
+
+	req, err := http.NewRequest("POST", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("X-Nonce", "abc")
+	recorder := httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusInternalServerError)
+
+	fail = false
+	recorder = httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusOK)
+}
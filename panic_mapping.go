@@ -0,0 +1,73 @@
+package negroni
+
+import (
+	"errors"
+	"net/http"
+	"runtime/debug"
+	"sync"
+)
+
+type panicMapping struct {
+	err     error
+	status  int
+	message string
+}
+
+var (
+	panicMappingsMu sync.RWMutex
+	panicMappings   []panicMapping
+)
+
+// RegisterPanicMapping registers a status code and message for handlers that panic with err.
+// A panic value matches when errors.Is reports it as err, so wrapped errors match and custom
+// error types can implement an Is method to match by type. Mappings are checked in the order
+// they were registered.
+func RegisterPanicMapping(err error, status int, message string) {
+	panicMappingsMu.Lock()
+	defer panicMappingsMu.Unlock()
+	panicMappings = append(panicMappings, panicMapping{err, status, message})
+}
+
+func lookupPanicMapping(v interface{}) (panicMapping, bool) {
+	err, ok := v.(error)
+	if !ok {
+		return panicMapping{}, false
+	}
+
+	panicMappingsMu.RLock()
+	defer panicMappingsMu.RUnlock()
+	for _, m := range panicMappings {
+		if errors.Is(err, m.err) {
+			return m, true
+		}
+	}
+	return panicMapping{}, false
+}
+
+// PanicMapping is a middleware handler that turns panics with registered errors into clean
+// responses, allowing an exception style of control flow. Panics that match no mapping are
+// re-raised as a *RecoveredPanic carrying the original stack, so that a Recovery earlier in
+// the stack logs them and writes a 500.
+type PanicMapping struct{}
+
+// NewPanicMapping returns a new instance of PanicMapping
+func NewPanicMapping() *PanicMapping {
+	return &PanicMapping{}
+}
+
+func (p *PanicMapping) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	defer func() {
+		if err := recover(); err != nil {
+			m, ok := lookupPanicMapping(err)
+			if !ok {
+				if _, ok := err.(*RecoveredPanic); !ok {
+					err = &RecoveredPanic{Value: err, Stack: debug.Stack()}
+				}
+				panic(err)
+			}
+			http.Error(rw, m.message, m.status)
+		}
+	}()
+
+	next(rw, r)
+}
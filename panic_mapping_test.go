@@ -0,0 +1,77 @@
+package negroni
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type notFoundError struct{}
+
+func (notFoundError) Error() string { return "not found" }
+
+func TestPanicMappingRegisteredError(t *testing.T) {
+	RegisterPanicMapping(notFoundError{}, http.StatusNotFound, "no such thing")
+
+	for _, v := range []interface{}{notFoundError{}, fmt.Errorf("loading user: %w", notFoundError{})} {
+		recorder := httptest.NewRecorder()
+
+		n := New()
+		n.Use(NewPanicMapping())
+		n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			panic(v)
+		})
+
+		n.ServeHTTP(recorder, (*http.Request)(nil))
+		expect(t, recorder.Code, http.StatusNotFound)
+		expect(t, recorder.Body.String(), "no such thing\n")
+	}
+}
+
+func TestPanicMappingUnknownPanic(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	rec := NewRecovery()
+	rec.Logger = log.New(bytes.NewBufferString(""), "[negroni] ", 0)
+	rec.PrintStack = false
+
+	n := New()
+	n.Use(rec)
+	n.Use(NewPanicMapping())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		panic("something else")
+	})
+
+	n.ServeHTTP(recorder, (*http.Request)(nil))
+	expect(t, recorder.Code, http.StatusInternalServerError)
+}
+
+func TestPanicMappingKeepsStack(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	var value interface{}
+	var stack []byte
+
+	rec := NewRecovery()
+	rec.Logger = log.New(bytes.NewBufferString(""), "[negroni] ", 0)
+	rec.PrintStack = false
+	rec.PanicHandler = func(r *http.Request, err interface{}, s []byte) {
+		value, stack = err, s
+	}
+
+	n := New()
+	n.Use(rec)
+	n.Use(NewPanicMapping())
+	n.Use(NewPanicMapping())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		panic("something else")
+	})
+
+	n.ServeHTTP(recorder, (*http.Request)(nil))
+	expect(t, recorder.Code, http.StatusInternalServerError)
+	expect(t, value, "something else")
+	expect(t, strings.Contains(string(stack), "TestPanicMappingKeepsStack.func2"), true)
+}
@@ -0,0 +1,68 @@
+package negroni
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// PathTraversal is a middleware handler that rejects requests whose path tries to climb out
+// of the directory it names with 400 Bad Request. The raw path sent by the client is checked
+// before any decoding or cleaning, so encoded ".." segments ("%2e%2e", "..%2f", "..%5c"),
+// double encoding ("%252e%252e") and null bytes ("%00") are all caught. It is meant to run
+// before Static or other handlers that map paths to files, as a second line of defense.
+type PathTraversal struct{}
+
+// NewPathTraversal returns a new instance of PathTraversal
+func NewPathTraversal() *PathTraversal {
+	return &PathTraversal{}
+}
+
+func (pt *PathTraversal) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if traversal(rawPath(r)) {
+		http.Error(rw, "invalid path", http.StatusBadRequest)
+		return
+	}
+
+	next(rw, r)
+}
+
+// rawPath returns the path of r as the client sent it.
+func rawPath(r *http.Request) string {
+	if r.RequestURI != "" {
+		if i := strings.IndexByte(r.RequestURI, '?'); i >= 0 {
+			return r.RequestURI[:i]
+		}
+		return r.RequestURI
+	}
+	if r.URL.RawPath != "" {
+		return r.URL.RawPath
+	}
+	return r.URL.Path
+}
+
+// traversal reports whether p contains a ".." segment or a null byte, decoding it up to
+// three times to see through repeated encoding. Paths that cannot be decoded are treated as
+// suspicious too.
+func traversal(p string) bool {
+	for i := 0; i < 3; i++ {
+		if strings.IndexByte(p, 0) >= 0 {
+			return true
+		}
+		segments := strings.FieldsFunc(p, func(c rune) bool { return c == '/' || c == '\\' })
+		for _, s := range segments {
+			if s == ".." {
+				return true
+			}
+		}
+		decoded, err := url.PathUnescape(p)
+		if err != nil {
+			return true
+		}
+		if decoded == p {
+			break
+		}
+		p = decoded
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPathTraversal(t *testing.T) {
+	n := New()
+	n.Use(NewPathTraversal())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusNoContent)
+	})
+
+	for _, path := range []string{
+		"/static/%2e%2e/%2e%2e/etc/passwd",
+		"/static/%2E%2E/secret",
+		"/static/..%2fsecret",
+		"/static/..%5csecret",
+		"/static/.%2e/secret",
+		"/static/%252e%252e/secret",
+		"/static/file.txt%00.png",
+		"/static/../secret",
+	} {
+		recorder := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "http://localhost:3000"+path, nil)
+		if err != nil {
+			t.Error(err)
+		}
+		n.ServeHTTP(recorder, req)
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", path, recorder.Code)
+		}
+	}
+
+	for _, path := range []string{"/static/app.js", "/static/a..b/c%20d.txt", "/static/..hidden"} {
+		recorder := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "http://localhost:3000"+path, nil)
+		if err != nil {
+			t.Error(err)
+		}
+		n.ServeHTTP(recorder, req)
+		if recorder.Code != http.StatusNoContent {
+			t.Errorf("Expected 204 for %s, got %d", path, recorder.Code)
+		}
+	}
+}
+
+func TestPathTraversalRequestURI(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	n.Use(NewPathTraversal())
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/static/secret", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	// The server keeps the path as sent in RequestURI, which is what gets checked.
+	req.RequestURI = "/static/%2e%2e/secret?q=1"
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusBadRequest)
+}
@@ -0,0 +1,66 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// ProcessingTime is a middleware handler that bounds how long requests take, for fairness and
+// abuse mitigation. Responses are held back until at least Min has passed since the request
+// arrived, which masks timing differences on endpoints such as login that would otherwise
+// reveal whether a user exists. Max caps processing by setting a deadline on the request
+// context, which handlers are expected to honor. The delay ends early if the request context
+// is cancelled.
+type ProcessingTime struct {
+	// Min is the minimum time before the response is written. Zero disables the delay.
+	Min time.Duration
+	// Max is the maximum processing time. Zero disables the cap.
+	Max time.Duration
+}
+
+// NewProcessingTime returns a new instance of ProcessingTime
+func NewProcessingTime(min, max time.Duration) *ProcessingTime {
+	return &ProcessingTime{Min: min, Max: max}
+}
+
+func (p *ProcessingTime) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	ctx := r.Context()
+	if p.Max > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = WithMaxDeadline(ctx, p.Max)
+		defer cancel()
+		r = r.WithContext(ctx)
+	}
+
+	if p.Min <= 0 {
+		next(rw, r)
+		return
+	}
+
+	earliest := time.Now().Add(p.Min)
+	res := rw.(ResponseWriter)
+	res.Before(func(ResponseWriter) {
+		sleepUntil(r.Context(), earliest)
+	})
+
+	next(rw, r)
+
+	if !res.Written() {
+		sleepUntil(r.Context(), earliest)
+	}
+}
+
+// sleepUntil waits until t or until ctx is done, whichever comes first.
+func sleepUntil(ctx context.Context, t time.Time) {
+	d := time.Until(t)
+	if d <= 0 {
+		return
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
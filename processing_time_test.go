@@ -0,0 +1,73 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestProcessingTimeMinimum(t *testing.T) {
+	min := 30 * time.Millisecond
+
+	n := New()
+	n.Use(NewProcessingTime(min, 0))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.Error(rw, "invalid credentials", http.StatusUnauthorized)
+		}
+	})
+
+	for _, path := range []string{"/login", "/empty"} {
+		recorder := httptest.NewRecorder()
+		req, err := http.NewRequest("POST", "http://localhost:3000"+path, nil)
+		if err != nil {
+			t.Error(err)
+		}
+		start := time.Now()
+		n.ServeHTTP(recorder, req)
+		if elapsed := time.Since(start); elapsed < min {
+			t.Errorf("Expected %s to take at least %v, took %v", path, min, elapsed)
+		}
+	}
+}
+
+func TestProcessingTimeMinimumCancelled(t *testing.T) {
+	n := New()
+	n.Use(NewProcessingTime(time.Hour, 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, err := http.NewRequest("POST", "http://localhost:3000/login", nil)
+	if err != nil {
+		t.Error(err)
+	}
+
+	start := time.Now()
+	n.ServeHTTP(httptest.NewRecorder(), req.WithContext(ctx))
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected a cancelled request not to be delayed, took %v", elapsed)
+	}
+}
+
+func TestProcessingTimeMaximum(t *testing.T) {
+	var remaining time.Duration
+
+	n := New()
+	n.Use(NewProcessingTime(0, 50*time.Millisecond))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		deadline, ok := r.Context().Deadline()
+		expect(t, ok, true)
+		remaining = time.Until(deadline)
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	if remaining > 50*time.Millisecond {
+		t.Errorf("Expected a deadline of at most 50ms, got %v", remaining)
+	}
+}
@@ -0,0 +1,119 @@
+package negroni
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/pprof"
+	"sync"
+)
+
+// Profiler is a middleware handler that profiles single requests on demand. A request from a
+// trusted source carrying Header with the value "cpu" gets a CPU profile in pprof format, and
+// one with "allocs" a summary of the memory allocated while it was served. The profile is
+// kept in memory under an ID returned in the response's Header, and can be downloaded from
+// Handler. Requests from untrusted sources are served normally whatever their headers.
+//
+// Only one CPU profile can be taken at a time in a process; while one is running, further
+// CPU profiling requests are served without profiling. Allocation figures are process wide,
+// so they include the work of concurrent requests.
+type Profiler struct {
+	// Header is the request header asking for a profile and the response header carrying
+	// its ID.
+	Header string
+	// Trusted lists the IP addresses or CIDR ranges allowed to ask for profiles.
+	Trusted []string
+	// N is the number of profiles kept. Older profiles are discarded first.
+	N int
+
+	mu       sync.Mutex
+	profiles map[string][]byte
+	order    []string
+}
+
+// NewProfiler returns a new instance of Profiler honoring the X-Profile header from the
+// trusted addresses or CIDR ranges and keeping the last 16 profiles
+func NewProfiler(trusted ...string) *Profiler {
+	return &Profiler{
+		Header:  "X-Profile",
+		Trusted: trusted,
+		N:       16,
+	}
+}
+
+func (p *Profiler) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	kind := r.Header.Get(p.Header)
+	if (kind != "cpu" && kind != "allocs") || !trustedClient(r, p.Trusted) {
+		next(rw, r)
+		return
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		next(rw, r)
+		return
+	}
+	rw.Header().Set(p.Header, id)
+
+	var profile bytes.Buffer
+	if kind == "cpu" {
+		if err := pprof.StartCPUProfile(&profile); err != nil {
+			rw.Header().Del(p.Header)
+			next(rw, r)
+			return
+		}
+		next(rw, r)
+		pprof.StopCPUProfile()
+	} else {
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		next(rw, r)
+		runtime.ReadMemStats(&after)
+		fmt.Fprintf(&profile, "alloc_bytes=%d mallocs=%d frees=%d\n",
+			after.TotalAlloc-before.TotalAlloc, after.Mallocs-before.Mallocs, after.Frees-before.Frees)
+	}
+
+	p.store(id, profile.Bytes())
+}
+
+// Get returns the profile stored under id.
+func (p *Profiler) Get(id string) ([]byte, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	data, ok := p.profiles[id]
+	return data, ok
+}
+
+// Handler returns an http.Handler serving the profile named by the id query parameter as a
+// download, to be mounted on an admin route.
+func (p *Profiler) Handler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("id")
+		data, ok := p.Get(id)
+		if !ok {
+			http.NotFound(rw, r)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/octet-stream")
+		rw.Header().Set("Content-Disposition", `attachment; filename="profile-`+id+`"`)
+		rw.Write(data)
+	})
+}
+
+func (p *Profiler) store(id string, data []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.N <= 0 {
+		return
+	}
+	if p.profiles == nil {
+		p.profiles = make(map[string][]byte)
+	}
+	p.profiles[id] = data
+	p.order = append(p.order, id)
+	for len(p.order) > p.N {
+		delete(p.profiles, p.order[0])
+		p.order = p.order[1:]
+	}
+}
@@ -0,0 +1,55 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProfiler(t *testing.T) {
+	p := NewProfiler("127.0.0.1")
+
+	n := New()
+	n.Use(p)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(strings.Repeat("x", 1024)))
+	})
+
+	serve := func(remoteAddr, kind string) string {
+		recorder := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+		if err != nil {
+			t.Error(err)
+		}
+		req.RemoteAddr = remoteAddr
+		if kind != "" {
+			req.Header.Set("X-Profile", kind)
+		}
+		n.ServeHTTP(recorder, req)
+		expect(t, recorder.Code, http.StatusOK)
+		return recorder.Header().Get("X-Profile")
+	}
+
+	expect(t, serve("127.0.0.1:5000", ""), "")
+	expect(t, serve("203.0.113.9:5000", "cpu"), "")
+	expect(t, serve("127.0.0.1:5000", "everything"), "")
+
+	id := serve("127.0.0.1:5000", "allocs")
+	refute(t, id, "")
+	data, ok := p.Get(id)
+	expect(t, ok, true)
+	expect(t, strings.HasPrefix(string(data), "alloc_bytes="), true)
+
+	id = serve("127.0.0.1:5000", "cpu")
+	refute(t, id, "")
+
+	recorder := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "http://localhost:3000/debug/profile?id="+id, nil)
+	if err != nil {
+		t.Error(err)
+	}
+	p.Handler().ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusOK)
+	refute(t, recorder.Body.Len(), 0)
+}
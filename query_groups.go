@@ -0,0 +1,69 @@
+package negroni
+
+import (
+	"net/http"
+	"strings"
+)
+
+// QueryGroups is a middleware handler that validates how query parameters are combined
+// before the handler sees them. Requests passing more than one parameter of an Exclusive
+// group, e.g. both since and before, or none of a Required group are rejected with 400 Bad
+// Request naming the parameters concerned. A parameter counts as passed even when its value
+// is empty.
+type QueryGroups struct {
+	// Exclusive lists groups of parameters of which at most one may be passed.
+	Exclusive [][]string
+	// Required lists groups of parameters of which at least one must be passed.
+	Required [][]string
+}
+
+// NewQueryGroups returns a new instance of QueryGroups without any groups
+func NewQueryGroups() *QueryGroups {
+	return &QueryGroups{}
+}
+
+// Conflict adds a group of parameters that cannot be combined and returns qg for chaining.
+func (qg *QueryGroups) Conflict(params ...string) *QueryGroups {
+	qg.Exclusive = append(qg.Exclusive, params)
+	return qg
+}
+
+// RequireOneOf adds a group of parameters of which at least one is required and returns qg
+// for chaining.
+func (qg *QueryGroups) RequireOneOf(params ...string) *QueryGroups {
+	qg.Required = append(qg.Required, params)
+	return qg
+}
+
+func (qg *QueryGroups) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	query := r.URL.Query()
+
+	for _, group := range qg.Exclusive {
+		var passed []string
+		for _, p := range group {
+			if _, ok := query[p]; ok {
+				passed = append(passed, p)
+			}
+		}
+		if len(passed) > 1 {
+			http.Error(rw, "query parameters cannot be combined: "+strings.Join(passed, ", "), http.StatusBadRequest)
+			return
+		}
+	}
+
+	for _, group := range qg.Required {
+		found := false
+		for _, p := range group {
+			if _, ok := query[p]; ok {
+				found = true
+				break
+			}
+		}
+		if !found {
+			http.Error(rw, "one of these query parameters is required: "+strings.Join(group, ", "), http.StatusBadRequest)
+			return
+		}
+	}
+
+	next(rw, r)
+}
@@ -0,0 +1,41 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestQueryGroups(t *testing.T) {
+	n := New()
+	n.Use(NewQueryGroups().
+		Conflict("since", "before", "around").
+		RequireOneOf("user", "team"))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusNoContent)
+	})
+
+	for _, c := range []struct {
+		query string
+		code  int
+		body  string
+	}{
+		{"user=1", http.StatusNoContent, ""},
+		{"team=2&since=10", http.StatusNoContent, ""},
+		{"user=1&team=2&before=", http.StatusNoContent, ""},
+		{"user=1&since=10&before=20", http.StatusBadRequest, "query parameters cannot be combined: since, before"},
+		{"user=1&around=5&since=", http.StatusBadRequest, "query parameters cannot be combined: since, around"},
+		{"since=10", http.StatusBadRequest, "one of these query parameters is required: user, team"},
+		{"", http.StatusBadRequest, "one of these query parameters is required: user, team"},
+	} {
+		recorder := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "http://localhost:3000/messages?"+c.query, nil)
+		if err != nil {
+			t.Error(err)
+		}
+		n.ServeHTTP(recorder, req)
+		expect(t, recorder.Code, c.code)
+		expect(t, strings.TrimSpace(recorder.Body.String()), c.body)
+	}
+}
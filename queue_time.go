@@ -0,0 +1,47 @@
+package negroni
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type queueTimeKey struct{}
+
+type queueTime struct {
+	mu sync.Mutex
+	d  time.Duration
+}
+
+// withQueueTime returns ctx with somewhere to record queue time, keeping the one already
+// there if any so that Logger sees the time recorded further down the stack.
+func withQueueTime(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(queueTimeKey{}).(*queueTime); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, queueTimeKey{}, &queueTime{})
+}
+
+// addQueueTime adds d to the queue time of the request ctx belongs to.
+func addQueueTime(ctx context.Context, d time.Duration) {
+	q, ok := ctx.Value(queueTimeKey{}).(*queueTime)
+	if !ok {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.d += d
+}
+
+// QueueTime returns how long the request ctx belongs to has waited for capacity in limiter
+// middleware such as ConcurrencyLimit, as opposed to being processed. Waits in several
+// limiters add up.
+func QueueTime(ctx context.Context) time.Duration {
+	q, ok := ctx.Value(queueTimeKey{}).(*queueTime)
+	if !ok {
+		return 0
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.d
+}
@@ -0,0 +1,17 @@
+package negroni
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestQueueTime(t *testing.T) {
+	addQueueTime(context.Background(), time.Second)
+	expect(t, QueueTime(context.Background()), time.Duration(0))
+
+	ctx := withQueueTime(context.Background())
+	addQueueTime(ctx, time.Second)
+	addQueueTime(withQueueTime(ctx), 2*time.Second)
+	expect(t, QueueTime(ctx), 3*time.Second)
+}
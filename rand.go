@@ -0,0 +1,21 @@
+package negroni
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+)
+
+// RandReader is the source of randomness used by middleware that generate request IDs,
+// nonces and tokens. It defaults to crypto/rand.Reader and may be replaced with a
+// deterministic reader in tests.
+var RandReader io.Reader = rand.Reader
+
+// randomHex reads n bytes from RandReader and returns them hex encoded.
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(RandReader, b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
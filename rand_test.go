@@ -0,0 +1,34 @@
+package negroni
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRandomHexFixedReader(t *testing.T) {
+	old := RandReader
+	defer func() { RandReader = old }()
+
+	RandReader = bytes.NewReader([]byte{0xde, 0xad, 0xbe, 0xef, 0x01, 0x02, 0x03, 0x04})
+
+	id, err := randomHex(4)
+	expect(t, err, nil)
+	expect(t, id, "deadbeef")
+
+	id, err = randomHex(4)
+	expect(t, err, nil)
+	expect(t, id, "01020304")
+
+	_, err = randomHex(4)
+	refute(t, err, nil)
+}
+
+func TestRandomHexDefaultReader(t *testing.T) {
+	a, err := randomHex(16)
+	expect(t, err, nil)
+	b, err := randomHex(16)
+	expect(t, err, nil)
+
+	expect(t, len(a), 32)
+	refute(t, a, b)
+}
@@ -0,0 +1,128 @@
+package negroni
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitStore records request times per key for a sliding window rate limiter.
+type RateLimitStore interface {
+	// Take records a request for key at now if fewer than limit requests were recorded in the
+	// window ending at now. It returns whether the request was recorded, the number of requests
+	// in the window afterwards and the time of the oldest one.
+	Take(key string, now time.Time, window time.Duration, limit int) (ok bool, count int, oldest time.Time)
+}
+
+// MemoryRateLimitStore is an in-memory RateLimitStore safe for concurrent use. It keeps the
+// time of every request within the window, so memory grows with the limit. Keys whose window
+// has fully elapsed are dropped by a sweep that runs at most once per PruneInterval, so the
+// store holds at most the keys seen during one window plus one PruneInterval.
+type MemoryRateLimitStore struct {
+	// PruneInterval is the minimum time between sweeps for idle keys.
+	PruneInterval time.Duration
+
+	mu        sync.Mutex
+	hits      map[string][]time.Time
+	lastPrune time.Time
+}
+
+// NewMemoryRateLimitStore returns a new, empty MemoryRateLimitStore sweeping idle keys once a
+// minute
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		PruneInterval: time.Minute,
+		hits:          make(map[string][]time.Time),
+		lastPrune:     time.Now(),
+	}
+}
+
+func (s *MemoryRateLimitStore) Take(key string, now time.Time, window time.Duration, limit int) (bool, int, time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastPrune) >= s.PruneInterval {
+		s.prune(now.Add(-window))
+		s.lastPrune = now
+	}
+
+	hits := s.hits[key]
+	start := now.Add(-window)
+	i := 0
+	for i < len(hits) && !hits[i].After(start) {
+		i++
+	}
+	hits = hits[i:]
+
+	ok := len(hits) < limit
+	if ok {
+		hits = append(hits, now)
+	}
+	if len(hits) == 0 {
+		delete(s.hits, key)
+		return ok, 0, now
+	}
+	s.hits[key] = hits
+	return ok, len(hits), hits[0]
+}
+
+// prune drops the keys without requests after start.
+func (s *MemoryRateLimitStore) prune(start time.Time) {
+	for key, hits := range s.hits {
+		if !hits[len(hits)-1].After(start) {
+			delete(s.hits, key)
+		}
+	}
+}
+
+// RateLimit is a middleware handler that limits requests per key with a sliding window, which
+// avoids the bursts fixed windows allow at their boundaries. Every response carries
+// X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset headers; requests over the
+// limit are rejected with 429 Too Many Requests.
+type RateLimit struct {
+	// Limit is the number of requests allowed per Window.
+	Limit  int
+	Window time.Duration
+	// Key returns the key requests are limited by. It defaults to the client IP.
+	Key   func(r *http.Request) string
+	Store RateLimitStore
+	// Now returns the current time and may be replaced in tests.
+	Now func() time.Time
+}
+
+// NewRateLimit returns a new instance of RateLimit allowing limit requests per window and client IP
+func NewRateLimit(limit int, window time.Duration) *RateLimit {
+	return &RateLimit{
+		Limit:  limit,
+		Window: window,
+		Key: func(r *http.Request) string {
+			return clientIP(r, false)
+		},
+		Store: NewMemoryRateLimitStore(),
+		Now:   time.Now,
+	}
+}
+
+func (rl *RateLimit) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	now := rl.Now()
+	ok, count, oldest := rl.Store.Take(rl.Key(r), now, rl.Window, rl.Limit)
+	reset := oldest.Add(rl.Window)
+
+	h := rw.Header()
+	h.Set("X-RateLimit-Limit", strconv.Itoa(rl.Limit))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(rl.Limit-count))
+	h.Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+
+	if !ok {
+		retry := int(reset.Sub(now) / time.Second)
+		if reset.Sub(now)%time.Second != 0 {
+			retry++
+		}
+		h.Set("Retry-After", strconv.Itoa(retry))
+		http.Error(rw, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		return
+	}
+
+	next(rw, r)
+}
@@ -0,0 +1,89 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRateLimitSlidingWindow(t *testing.T) {
+	now := time.Unix(1000, 0)
+	rl := NewRateLimit(2, 10*time.Second)
+	rl.Now = func() time.Time { return now }
+
+	n := New()
+	n.Use(rl)
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.RemoteAddr = "10.0.0.1:1234"
+
+	serve := func() *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		n.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	recorder := serve()
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, recorder.Header().Get("X-RateLimit-Limit"), "2")
+	expect(t, recorder.Header().Get("X-RateLimit-Remaining"), "1")
+	expect(t, recorder.Header().Get("X-RateLimit-Reset"), strconv.FormatInt(1010, 10))
+
+	now = now.Add(4 * time.Second)
+	recorder = serve()
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, recorder.Header().Get("X-RateLimit-Remaining"), "0")
+
+	now = now.Add(4 * time.Second)
+	recorder = serve()
+	expect(t, recorder.Code, http.StatusTooManyRequests)
+	expect(t, recorder.Header().Get("X-RateLimit-Remaining"), "0")
+	expect(t, recorder.Header().Get("X-RateLimit-Reset"), "1010")
+	expect(t, recorder.Header().Get("Retry-After"), "2")
+
+	// The first request slides out of the window, the second one is still in it.
+	now = now.Add(3 * time.Second)
+	recorder = serve()
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, recorder.Header().Get("X-RateLimit-Remaining"), "0")
+	expect(t, recorder.Header().Get("X-RateLimit-Reset"), "1014")
+}
+
+func TestRateLimitPerKey(t *testing.T) {
+	rl := NewRateLimit(1, time.Minute)
+
+	n := New()
+	n.Use(rl)
+
+	for _, addr := range []string{"10.0.0.1:1", "10.0.0.2:1"} {
+		req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+		if err != nil {
+			t.Error(err)
+		}
+		req.RemoteAddr = addr
+
+		recorder := httptest.NewRecorder()
+		n.ServeHTTP(recorder, req)
+		expect(t, recorder.Code, http.StatusOK)
+	}
+}
+
+func TestMemoryRateLimitStoreEvictsIdleKeys(t *testing.T) {
+	s := NewMemoryRateLimitStore()
+	s.PruneInterval = 2 * time.Second
+	start := time.Now()
+
+	s.Take("a", start, time.Second, 5)
+	s.Take("b", start.Add(1500*time.Millisecond), time.Second, 5)
+	expect(t, len(s.hits), 2)
+
+	s.Take("c", start.Add(2*time.Second), time.Second, 5)
+	expect(t, len(s.hits), 2)
+	_, ok := s.hits["a"]
+	expect(t, ok, false)
+}
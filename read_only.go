@@ -0,0 +1,48 @@
+package negroni
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// ReadOnly is a middleware handler that, while enabled, rejects requests with mutating
+// methods with 503 Service Unavailable and lets GET, HEAD and OPTIONS requests through. It
+// is meant for migrations or incidents where writes must be paused but reads keep working.
+type ReadOnly struct {
+	// Message is written as the body of rejected requests.
+	Message string
+
+	enabled int32
+}
+
+// NewReadOnly returns a new instance of ReadOnly that is disabled
+func NewReadOnly() *ReadOnly {
+	return &ReadOnly{Message: "service is in read-only mode"}
+}
+
+// SetEnabled turns read-only mode on or off. It is safe to call from any goroutine.
+func (ro *ReadOnly) SetEnabled(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&ro.enabled, v)
+}
+
+// Enabled reports whether read-only mode is on.
+func (ro *ReadOnly) Enabled() bool {
+	return atomic.LoadInt32(&ro.enabled) == 1
+}
+
+func (ro *ReadOnly) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if ro.Enabled() {
+		switch r.Method {
+		case "GET", "HEAD", "OPTIONS":
+		default:
+			http.Error(rw, ro.Message, http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	next(rw, r)
+}
@@ -0,0 +1,48 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func serveReadOnly(t *testing.T, ro *ReadOnly, method string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	n.Use(ro)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	req, err := http.NewRequest(method, "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestReadOnlyEnabled(t *testing.T) {
+	ro := NewReadOnly()
+	ro.SetEnabled(true)
+	expect(t, ro.Enabled(), true)
+
+	for _, method := range []string{"POST", "PUT", "PATCH", "DELETE"} {
+		recorder := serveReadOnly(t, ro, method)
+		expect(t, recorder.Code, http.StatusServiceUnavailable)
+		expect(t, recorder.Body.String(), "service is in read-only mode\n")
+	}
+	for _, method := range []string{"GET", "HEAD", "OPTIONS"} {
+		expect(t, serveReadOnly(t, ro, method).Code, http.StatusOK)
+	}
+}
+
+func TestReadOnlyDisabled(t *testing.T) {
+	ro := NewReadOnly()
+	expect(t, ro.Enabled(), false)
+
+	for _, method := range []string{"GET", "POST", "DELETE"} {
+		expect(t, serveReadOnly(t, ro, method).Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,84 @@
+package negroni
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// PanicRecord describes a panic kept by RecentPanics.
+type PanicRecord struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+	Stack   string    `json:"stack"`
+	Method  string    `json:"method,omitempty"`
+	URL     string    `json:"url,omitempty"`
+}
+
+// RecentPanics keeps the last N panics recovered by a Recovery middleware in a ring buffer, so
+// they can be inspected without digging through logs. Set it as Recovery.Recent and read it
+// with Panics, or mount it as an http.Handler on an admin route to get the panics as JSON.
+type RecentPanics struct {
+	// N is the number of panics kept.
+	N int
+	// MaxAge is how long a panic is kept. Zero keeps panics until newer ones displace them.
+	MaxAge time.Duration
+
+	mu      sync.Mutex
+	records []PanicRecord
+	next    int
+}
+
+// NewRecentPanics returns a new instance of RecentPanics keeping the last n panics of the last day
+func NewRecentPanics(n int) *RecentPanics {
+	return &RecentPanics{N: n, MaxAge: 24 * time.Hour}
+}
+
+// Panics returns the recorded panics, newest first.
+func (p *RecentPanics) Panics() []PanicRecord {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	panics := make([]PanicRecord, 0, len(p.records))
+	for i := 1; i <= len(p.records); i++ {
+		rec := p.records[(p.next-i+len(p.records))%len(p.records)]
+		if p.MaxAge > 0 && now.Sub(rec.Time) > p.MaxAge {
+			continue
+		}
+		panics = append(panics, rec)
+	}
+	return panics
+}
+
+func (p *RecentPanics) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(rw).Encode(p.Panics())
+}
+
+func (p *RecentPanics) record(r *http.Request, err interface{}, stack []byte) {
+	rec := PanicRecord{
+		Time:    time.Now(),
+		Message: fmt.Sprint(err),
+		Stack:   string(stack),
+	}
+	if r != nil {
+		rec.Method = r.Method
+		rec.URL = r.URL.String()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.N <= 0 {
+		return
+	}
+	if len(p.records) < p.N {
+		p.records = append(p.records, rec)
+		p.next = len(p.records) % p.N
+		return
+	}
+	p.records[p.next] = rec
+	p.next = (p.next + 1) % len(p.records)
+}
@@ -0,0 +1,57 @@
+package negroni
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecentPanics(t *testing.T) {
+	recent := NewRecentPanics(2)
+
+	rec := NewRecovery()
+	rec.Logger = log.New(bytes.NewBufferString(""), "[negroni] ", 0)
+	rec.Recent = recent
+
+	n := New()
+	n.Use(rec)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		panic("boom " + r.URL.Path)
+	})
+
+	for i := 0; i < 3; i++ {
+		req, err := http.NewRequest("GET", fmt.Sprintf("http://localhost:3000/%d", i), nil)
+		if err != nil {
+			t.Error(err)
+		}
+		n.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	panics := recent.Panics()
+	expect(t, len(panics), 2)
+	expect(t, panics[0].Message, "boom /2")
+	expect(t, panics[0].Method, "GET")
+	expect(t, panics[0].URL, "http://localhost:3000/2")
+	expect(t, panics[1].Message, "boom /1")
+	expect(t, strings.Contains(panics[0].Stack, "goroutine"), true)
+
+	recorder := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "http://localhost:3000/debug/panics", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	recent.ServeHTTP(recorder, req)
+	expect(t, recorder.Header().Get("Content-Type"), "application/json")
+
+	var decoded []PanicRecord
+	if err := json.NewDecoder(recorder.Body).Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	expect(t, len(decoded), 2)
+	expect(t, decoded[1].Message, "boom /1")
+}
@@ -0,0 +1,59 @@
+package negroni
+
+import (
+	"context"
+	"fmt"
+)
+
+// PanicKey is the context key under which Recovery stores a *RecoveredPanic.
+//
+// The context flows forward through the stack while recovery happens on the way back, so
+// where the value can be read depends on the order of the stack:
+//
+//   - Recovery's own PanicHandlerFunc and PanicHandler can always read it from the request
+//     they are given.
+//   - Handlers after Recovery share the value, but it is only filled in once Recovery has
+//     recovered, after they have returned.
+//   - Handlers before Recovery, such as a recovery-aware logger, must install an empty
+//     &RecoveredPanic{} under PanicKey{} in the request context before calling next;
+//     Recovery then fills in that value, which they can read once next returns.
+type PanicKey struct{}
+
+// RecoveredPanic holds a panic recovered by Recovery.
+//
+// Middleware that recover a panic they cannot handle, or that recover it on another goroutine,
+// raise it again as a *RecoveredPanic carrying the stack of the original panic. Recovery
+// unwraps it, so it logs and reports that stack rather than its own.
+type RecoveredPanic struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (p *RecoveredPanic) Error() string {
+	return fmt.Sprintf("%v\n%s", p.Value, p.Stack)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (p *RecoveredPanic) Unwrap() error {
+	err, _ := p.Value.(error)
+	return err
+}
+
+// PanicFromContext returns the panic Recovery recovered for the request ctx belongs to, or
+// nil if there was none.
+func PanicFromContext(ctx context.Context) *RecoveredPanic {
+	p, ok := ctx.Value(PanicKey{}).(*RecoveredPanic)
+	if !ok || p.Value == nil {
+		return nil
+	}
+	return p
+}
+
+// withRecoveredPanic makes sure ctx carries a *RecoveredPanic for Recovery to fill in.
+func withRecoveredPanic(ctx context.Context) (context.Context, *RecoveredPanic) {
+	if p, ok := ctx.Value(PanicKey{}).(*RecoveredPanic); ok {
+		return ctx, p
+	}
+	p := &RecoveredPanic{}
+	return context.WithValue(ctx, PanicKey{}, p), p
+}
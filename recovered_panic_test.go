@@ -0,0 +1,43 @@
+package negroni
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPanicFromContext(t *testing.T) {
+	var outer, fromHandler *RecoveredPanic
+
+	rec := NewRecovery()
+	rec.Logger = log.New(bytes.NewBufferString(""), "[negroni] ", 0)
+	rec.PanicHandler = func(r *http.Request, err interface{}, stack []byte) {
+		fromHandler = PanicFromContext(r.Context())
+	}
+
+	n := New()
+	// A recovery-aware handler placed before Recovery installs the value itself.
+	n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		p := &RecoveredPanic{}
+		next(rw, r.WithContext(context.WithValue(r.Context(), PanicKey{}, p)))
+		outer = p
+	})
+	n.Use(rec)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		expect(t, PanicFromContext(r.Context()), (*RecoveredPanic)(nil))
+		panic("here is a panic!")
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(httptest.NewRecorder(), req)
+
+	expect(t, outer.Value, "here is a panic!")
+	refute(t, len(outer.Stack), 0)
+	expect(t, fromHandler, outer)
+}
@@ -1,19 +1,53 @@
 package negroni
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"runtime"
+	"strconv"
+	"strings"
+	"sync"
 )
 
+// DefaultPanicHandler, if set, is called with every panic recovered by a Recovery middleware
+// that has no PanicHandler of its own. It is useful for reporting panics to an error tracking
+// service once for the whole application.
+var DefaultPanicHandler func(r *http.Request, err interface{}, stack []byte)
+
 // Recovery is a Negroni middleware that recovers from any panics and writes a 500 if there was one.
+// If the response was already committed when the handler panicked, the panic is only logged.
+//
+// By default the panic and its stack trace are written to the response as well as the log,
+// which is convenient in development but discloses internals to clients. Use
+// NewRecoveryProduction, or set ProductionMode, for services exposed to untrusted clients.
 type Recovery struct {
-	Logger     *log.Logger
+	Logger *log.Logger
+	// PrintStack writes the panic and stack trace to the response body.
 	PrintStack bool
 	StackAll   bool
 	StackSize  int
+	// ProductionMode answers panics with a plain "Internal Server Error" body regardless of
+	// PrintStack, so no details reach the client. The log still gets the full stack trace.
+	ProductionMode bool
+	// PanicHandler is called with the recovered value and stack after the panic has been
+	// logged. It overrides DefaultPanicHandler when set.
+	PanicHandler func(r *http.Request, err interface{}, stack []byte)
+	// PanicHandlerFunc, if set, writes the response for a recovered panic instead of the
+	// default 500 and stack dump, e.g. to render a branded error page. No status is written
+	// for it, so it must choose one. The panic is still logged and passed to PanicHandler.
+	PanicHandlerFunc func(ctx context.Context, rw http.ResponseWriter, r *http.Request, err interface{}, stack []byte)
+	// PoolStack reuses stack buffers between panics instead of allocating StackSize bytes
+	// every time. When set, the stack passed to PanicHandler and PanicHandlerFunc is only
+	// valid during the call, and RecentPanics copies it when recording. The RecoveredPanic in
+	// the request context always gets its own copy.
+	PoolStack bool
+	// Recent, if set, keeps the latest recovered panics for inspection.
+	Recent *RecentPanics
+
+	stackPool sync.Pool
 }
 
 // NewRecovery returns a new instance of Recovery
@@ -26,21 +60,119 @@ func NewRecovery() *Recovery {
 	}
 }
 
+// NewRecoveryProduction returns a new instance of Recovery in ProductionMode, which logs the
+// stack trace of panics but keeps it out of responses
+func NewRecoveryProduction() *Recovery {
+	rec := NewRecovery()
+	rec.PrintStack = false
+	rec.ProductionMode = true
+	return rec
+}
+
 func (rec *Recovery) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	var recovered *RecoveredPanic
+	if r != nil {
+		var ctx context.Context
+		ctx, recovered = withRecoveredPanic(withBreadcrumbs(r.Context()))
+		r = r.WithContext(ctx)
+	}
+
 	defer func() {
 		if err := recover(); err != nil {
-			rw.WriteHeader(http.StatusInternalServerError)
-			stack := make([]byte, rec.StackSize)
-			stack = stack[:runtime.Stack(stack, rec.StackAll)]
+			buf := rec.getStackBuffer()
+			defer rec.putStackBuffer(buf)
+			stack := (*buf)[:runtime.Stack(*buf, rec.StackAll)]
+			if p, ok := err.(*RecoveredPanic); ok {
+				err, stack = p.Value, p.Stack
+			}
+			if recovered != nil {
+				recovered.Value = err
+				recovered.Stack = stack
+				if rec.PoolStack {
+					// The pooled buffer is reused once this returns.
+					recovered.Stack = append([]byte(nil), stack...)
+				}
+			}
 
 			f := "PANIC: %s\n%s"
-			rec.Logger.Printf(f, err, stack)
+			msg := fmt.Sprintf(f, err, stack)
+			if r != nil {
+				if crumbs := Breadcrumbs(r.Context()); len(crumbs) > 0 {
+					msg += "\nBreadcrumbs:\n  " + strings.Join(crumbs, "\n  ")
+				}
+			}
+			// Once the status and maybe part of the body are out, writing a 500 would only
+			// corrupt the response, so the panic is just logged.
+			res, ok := rw.(ResponseWriter)
+			committed := ok && res.Written()
+			if committed {
+				msg += "\nResponse already committed with status " + strconv.Itoa(res.Status())
+			}
+			rec.Logger.Print(msg)
+			if rec.Recent != nil {
+				rec.Recent.record(r, err, stack)
+			}
 
-			if rec.PrintStack {
-				fmt.Fprintf(rw, f, err, stack)
+			switch {
+			case committed:
+			case rec.PanicHandlerFunc != nil:
+				ctx := context.Background()
+				if r != nil {
+					ctx = r.Context()
+				}
+				rec.PanicHandlerFunc(ctx, rw, r, err, stack)
+			case rec.ProductionMode:
+				http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			default:
+				rw.WriteHeader(http.StatusInternalServerError)
+				if rec.PrintStack {
+					fmt.Fprintf(rw, f, err, stack)
+				}
+			}
+
+			if handler := rec.panicHandler(); handler != nil {
+				handler(r, err, stack)
 			}
 		}
 	}()
 
 	next(rw, r)
 }
+
+func (rec *Recovery) panicHandler() func(*http.Request, interface{}, []byte) {
+	if rec.PanicHandler != nil {
+		return rec.PanicHandler
+	}
+	return DefaultPanicHandler
+}
+
+func (rec *Recovery) getStackBuffer() *[]byte {
+	if rec.PoolStack {
+		if buf, ok := rec.stackPool.Get().(*[]byte); ok && len(*buf) == rec.StackSize {
+			return buf
+		}
+	}
+	buf := make([]byte, rec.StackSize)
+	return &buf
+}
+
+func (rec *Recovery) putStackBuffer(buf *[]byte) {
+	if rec.PoolStack {
+		rec.stackPool.Put(buf)
+	}
+}
+
+// Describe summarizes the Recovery configuration for Negroni.DescribeJSON.
+func (rec *Recovery) Describe() HandlerDescriptor {
+	return HandlerDescriptor{Config: map[string]interface{}{
+		"print_stack":     rec.PrintStack,
+		"production_mode": rec.ProductionMode,
+		"stack_all":       rec.StackAll,
+		"stack_size":      rec.StackSize,
+	}}
+}
+
+// Role identifies Recovery to Negroni.Validate.
+func (rec *Recovery) Role() HandlerRole {
+	return RoleRecovery
+}
@@ -2,9 +2,12 @@ package negroni
 
 import (
 	"bytes"
+	"context"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -26,3 +29,195 @@ func TestRecovery(t *testing.T) {
 	refute(t, recorder.Body.Len(), 0)
 	refute(t, len(buff.String()), 0)
 }
+
+func TestRecoveryProduction(t *testing.T) {
+	buff := bytes.NewBufferString("")
+	recorder := httptest.NewRecorder()
+
+	rec := NewRecoveryProduction()
+	rec.Logger = log.New(buff, "[negroni] ", 0)
+
+	n := New()
+	n.Use(rec)
+	n.UseHandler(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		panic("database password is hunter2")
+	}))
+	n.ServeHTTP(recorder, (*http.Request)(nil))
+	expect(t, recorder.Code, http.StatusInternalServerError)
+	expect(t, recorder.Body.String(), "Internal Server Error\n")
+	expect(t, strings.Contains(buff.String(), "PANIC: database password is hunter2"), true)
+	expect(t, strings.Contains(buff.String(), "goroutine"), true)
+}
+
+func TestRecoveryProductionModeOverridesPrintStack(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	rec := NewRecovery()
+	rec.Logger = log.New(bytes.NewBufferString(""), "[negroni] ", 0)
+	rec.ProductionMode = true
+
+	n := New()
+	n.Use(rec)
+	n.UseHandler(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		panic("here is a panic!")
+	}))
+	n.ServeHTTP(recorder, (*http.Request)(nil))
+	expect(t, recorder.Code, http.StatusInternalServerError)
+	expect(t, strings.Contains(recorder.Body.String(), "here is a panic!"), false)
+}
+
+func TestRecoveryAfterPartialWrite(t *testing.T) {
+	buff := bytes.NewBufferString("")
+	recorder := httptest.NewRecorder()
+
+	rec := NewRecovery()
+	rec.Logger = log.New(buff, "[negroni] ", 0)
+	handled := false
+	rec.PanicHandlerFunc = func(ctx context.Context, rw http.ResponseWriter, r *http.Request, err interface{}, stack []byte) {
+		handled = true
+	}
+
+	n := New()
+	n.Use(rec)
+	n.UseHandler(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+		res.Write([]byte("partial"))
+		panic("here is a panic!")
+	}))
+	n.ServeHTTP(recorder, (*http.Request)(nil))
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, recorder.Body.String(), "partial")
+	expect(t, handled, false)
+	expect(t, strings.Contains(buff.String(), "PANIC: here is a panic!"), true)
+	expect(t, strings.Contains(buff.String(), "Response already committed with status 200"), true)
+}
+
+func TestRecoveryDefaultPanicHandler(t *testing.T) {
+	old := DefaultPanicHandler
+	defer func() { DefaultPanicHandler = old }()
+
+	var reported interface{}
+	DefaultPanicHandler = func(r *http.Request, err interface{}, stack []byte) {
+		reported = err
+		refute(t, len(stack), 0)
+	}
+
+	rec := NewRecovery()
+	rec.Logger = log.New(bytes.NewBufferString(""), "[negroni] ", 0)
+
+	n := New()
+	n.Use(rec)
+	n.UseHandler(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		panic("here is a panic!")
+	}))
+	n.ServeHTTP(httptest.NewRecorder(), (*http.Request)(nil))
+	expect(t, reported, "here is a panic!")
+}
+
+func TestRecoveryPanicHandlerOverridesDefault(t *testing.T) {
+	old := DefaultPanicHandler
+	defer func() { DefaultPanicHandler = old }()
+
+	globalCalled := false
+	DefaultPanicHandler = func(r *http.Request, err interface{}, stack []byte) {
+		globalCalled = true
+	}
+
+	localCalled := false
+	rec := NewRecovery()
+	rec.Logger = log.New(bytes.NewBufferString(""), "[negroni] ", 0)
+	rec.PanicHandler = func(r *http.Request, err interface{}, stack []byte) {
+		localCalled = true
+	}
+
+	n := New()
+	n.Use(rec)
+	n.UseHandler(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		panic("here is a panic!")
+	}))
+	n.ServeHTTP(httptest.NewRecorder(), (*http.Request)(nil))
+	expect(t, localCalled, true)
+	expect(t, globalCalled, false)
+}
+
+func benchmarkRecovery(b *testing.B, pool bool) {
+	rec := NewRecovery()
+	rec.Logger = log.New(ioutil.Discard, "[negroni] ", 0)
+	rec.PrintStack = false
+	rec.PoolStack = pool
+
+	n := New()
+	n.Use(rec)
+	n.UseHandler(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		panic("here is a panic!")
+	}))
+
+	recorder := httptest.NewRecorder()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		n.ServeHTTP(recorder, (*http.Request)(nil))
+	}
+}
+
+func BenchmarkRecovery(b *testing.B) {
+	benchmarkRecovery(b, false)
+}
+
+func BenchmarkRecoveryPoolStack(b *testing.B) {
+	benchmarkRecovery(b, true)
+}
+
+func TestRecoveryPoolStack(t *testing.T) {
+	buff := bytes.NewBufferString("")
+
+	rec := NewRecovery()
+	rec.Logger = log.New(buff, "[negroni] ", 0)
+	rec.PoolStack = true
+
+	n := New()
+	n.Use(rec)
+	n.UseHandler(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		panic("here is a panic!")
+	}))
+
+	for i := 0; i < 2; i++ {
+		recorder := httptest.NewRecorder()
+		n.ServeHTTP(recorder, (*http.Request)(nil))
+		expect(t, recorder.Code, http.StatusInternalServerError)
+		expect(t, strings.HasPrefix(recorder.Body.String(), "PANIC: here is a panic!\n"), true)
+	}
+	expect(t, strings.Count(buff.String(), "PANIC: here is a panic!"), 2)
+}
+
+func TestRecoveryPanicHandlerFunc(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	var reported interface{}
+
+	rec := NewRecovery()
+	rec.Logger = log.New(bytes.NewBufferString(""), "[negroni] ", 0)
+	rec.PanicHandlerFunc = func(ctx context.Context, rw http.ResponseWriter, r *http.Request, err interface{}, stack []byte) {
+		refute(t, ctx, nil)
+		refute(t, len(stack), 0)
+		rw.WriteHeader(http.StatusServiceUnavailable)
+		rw.Write([]byte("<h1>Something went wrong</h1>"))
+	}
+	rec.PanicHandler = func(r *http.Request, err interface{}, stack []byte) {
+		reported = err
+	}
+
+	n := New()
+	n.Use(rec)
+	n.UseHandler(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		panic("here is a panic!")
+	}))
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusServiceUnavailable)
+	expect(t, recorder.Body.String(), "<h1>Something went wrong</h1>")
+	expect(t, reported, "here is a panic!")
+}
@@ -0,0 +1,98 @@
+package negroni
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type requestAttributesKey struct{}
+
+// RequestAttributes holds commonly derived request metadata so that middleware further down
+// the chain can share a single context lookup instead of each storing its own value.
+type RequestAttributes struct {
+	clientIP  string
+	scheme    string
+	requestID string
+	start     time.Time
+}
+
+// ClientIP returns the IP address of the client that made the request.
+func (a *RequestAttributes) ClientIP() string { return a.clientIP }
+
+// Scheme returns "https" or "http".
+func (a *RequestAttributes) Scheme() string { return a.scheme }
+
+// RequestID returns the request identifier, taken from X-Request-ID or generated.
+func (a *RequestAttributes) RequestID() string { return a.requestID }
+
+// StartTime returns the time the request entered the RequestAttributer middleware.
+func (a *RequestAttributes) StartTime() time.Time { return a.start }
+
+// AttributesFromContext returns the RequestAttributes stored by a RequestAttributer, or nil.
+func AttributesFromContext(ctx context.Context) *RequestAttributes {
+	a, _ := ctx.Value(requestAttributesKey{}).(*RequestAttributes)
+	return a
+}
+
+// RequestAttributer is a middleware handler that populates RequestAttributes once per request
+// and stores them in the request context. It should be added early in the stack.
+type RequestAttributer struct {
+	// TrustProxy makes the client IP and scheme honor X-Forwarded-For, X-Real-IP and
+	// X-Forwarded-Proto. Only enable it behind a proxy that sets these headers, as clients
+	// can otherwise spoof them.
+	TrustProxy bool
+}
+
+// NewRequestAttributer returns a new instance of RequestAttributer
+func NewRequestAttributer() *RequestAttributer {
+	return &RequestAttributer{}
+}
+
+func (ra *RequestAttributer) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	a := &RequestAttributes{
+		clientIP:  clientIP(r, ra.TrustProxy),
+		scheme:    "http",
+		requestID: r.Header.Get("X-Request-ID"),
+		start:     time.Now(),
+	}
+	if r.TLS != nil {
+		a.scheme = "https"
+	} else if proto := r.Header.Get("X-Forwarded-Proto"); ra.TrustProxy && proto != "" {
+		a.scheme = strings.ToLower(proto)
+	}
+	if a.requestID == "" {
+		a.requestID, _ = randomHex(16)
+	}
+
+	next(rw, r.WithContext(context.WithValue(r.Context(), requestAttributesKey{}, a)))
+}
+
+// clientIP returns the normalized address of the client, honoring proxy headers when
+// trustProxy is set. X-Forwarded-For may list several hops; the left-most public address is
+// taken, or the left-most address if they are all private.
+func clientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+			hops := strings.Split(xff, ",")
+			for _, hop := range hops {
+				if ip := net.ParseIP(NormalizeIP(hop)); ip != nil && !privateIP(ip) {
+					return ip.String()
+				}
+			}
+			return NormalizeIP(hops[0])
+		}
+		if ip := r.Header.Get("X-Real-IP"); ip != "" {
+			return NormalizeIP(ip)
+		}
+	}
+	return NormalizeIP(r.RemoteAddr)
+}
+
+// privateIP reports whether ip is a loopback, link-local, private or unspecified address,
+// such as those of proxies inside the network.
+func privateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsPrivate() || ip.IsUnspecified()
+}
@@ -0,0 +1,98 @@
+package negroni
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRequestAttributes(t *testing.T) {
+	var attrs *RequestAttributes
+	before := time.Now()
+
+	n := New()
+	n.Use(NewRequestAttributer())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		attrs = AttributesFromContext(r.Context())
+	})
+
+	req, err := http.NewRequest("GET", "https://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.RemoteAddr = "10.1.2.3:4567"
+	req.TLS = &tls.ConnectionState{}
+	req.Header.Set("X-Request-ID", "abc123")
+
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	refute(t, attrs, (*RequestAttributes)(nil))
+	expect(t, attrs.ClientIP(), "10.1.2.3")
+	expect(t, attrs.Scheme(), "https")
+	expect(t, attrs.RequestID(), "abc123")
+	expect(t, attrs.StartTime().Before(before), false)
+}
+
+func TestRequestAttributesTrustProxy(t *testing.T) {
+	var attrs *RequestAttributes
+
+	ra := NewRequestAttributer()
+	ra.TrustProxy = true
+
+	n := New()
+	n.Use(ra)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		attrs = AttributesFromContext(r.Context())
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.RemoteAddr = "10.1.2.3:4567"
+	req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+	req.Header.Set("X-Forwarded-Proto", "HTTPS")
+
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, attrs.ClientIP(), "203.0.113.7")
+	expect(t, attrs.Scheme(), "https")
+	expect(t, len(attrs.RequestID()), 32)
+}
+
+func TestRequestAttributesNormalizesClientIP(t *testing.T) {
+	var ip string
+
+	n := New()
+	n.Use(NewRequestAttributer())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		ip = AttributesFromContext(r.Context()).ClientIP()
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.RemoteAddr = "[::ffff:10.0.0.7]:51234"
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, ip, "10.0.0.7")
+}
+
+func TestClientIPForwardedHops(t *testing.T) {
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.RemoteAddr = "10.1.2.3:4567"
+
+	req.Header.Set("X-Forwarded-For", "192.168.1.20, 198.51.100.4, 10.0.0.1")
+	expect(t, clientIP(req, true), "198.51.100.4")
+	expect(t, clientIP(req, false), "10.1.2.3")
+
+	req.Header.Set("X-Forwarded-For", "192.168.1.20, 10.0.0.1")
+	expect(t, clientIP(req, true), "192.168.1.20")
+
+	req.Header.Del("X-Forwarded-For")
+	req.Header.Set("X-Real-IP", "198.51.100.9")
+	expect(t, clientIP(req, true), "198.51.100.9")
+}
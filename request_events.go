@@ -0,0 +1,97 @@
+package negroni
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RequestEventKind tells whether a RequestEvent marks the start or the end of a request.
+type RequestEventKind int
+
+const (
+	// RequestStarted is published when a request enters the RequestEvents middleware.
+	RequestStarted RequestEventKind = iota
+	// RequestFinished is published when the rest of the chain has returned.
+	RequestFinished
+)
+
+// RequestEvent is a lightweight description of a request published by RequestEvents.
+type RequestEvent struct {
+	Kind   RequestEventKind
+	Method string
+	Path   string
+	Time   time.Time
+	// Status and Duration are only set for RequestFinished events.
+	Status   int
+	Duration time.Duration
+}
+
+// RequestEvents is a middleware handler that publishes request start and finish events to
+// subscribers, powering live "tail" views without scraping logs. Publishing never blocks: an
+// event is dropped for a subscriber whose channel is full.
+type RequestEvents struct {
+	mu          sync.RWMutex
+	subscribers map[chan RequestEvent]struct{}
+	dropped     uint64
+}
+
+// NewRequestEvents returns a new instance of RequestEvents with no subscribers
+func NewRequestEvents() *RequestEvents {
+	return &RequestEvents{subscribers: make(map[chan RequestEvent]struct{})}
+}
+
+// Subscribe returns a channel receiving events, buffering up to size of them.
+func (e *RequestEvents) Subscribe(size int) <-chan RequestEvent {
+	ch := make(chan RequestEvent, size)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.subscribers[ch] = struct{}{}
+	return ch
+}
+
+// Unsubscribe stops delivering events to ch and closes it.
+func (e *RequestEvents) Unsubscribe(ch <-chan RequestEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for sub := range e.subscribers {
+		if sub == ch {
+			delete(e.subscribers, sub)
+			close(sub)
+		}
+	}
+}
+
+// Dropped returns the number of events dropped because a subscriber was not keeping up.
+func (e *RequestEvents) Dropped() uint64 {
+	return atomic.LoadUint64(&e.dropped)
+}
+
+func (e *RequestEvents) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	start := time.Now()
+	e.publish(RequestEvent{Kind: RequestStarted, Method: r.Method, Path: r.URL.Path, Time: start})
+
+	next(rw, r)
+
+	e.publish(RequestEvent{
+		Kind:     RequestFinished,
+		Method:   r.Method,
+		Path:     r.URL.Path,
+		Time:     time.Now(),
+		Status:   finalStatus(rw.(ResponseWriter)),
+		Duration: time.Since(start),
+	})
+}
+
+func (e *RequestEvents) publish(event RequestEvent) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for sub := range e.subscribers {
+		select {
+		case sub <- event:
+		default:
+			atomic.AddUint64(&e.dropped, 1)
+		}
+	}
+}
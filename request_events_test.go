@@ -0,0 +1,54 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestEvents(t *testing.T) {
+	events := NewRequestEvents()
+	ch := events.Subscribe(10)
+
+	n := New()
+	n.Use(events)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusCreated)
+	})
+
+	req, err := http.NewRequest("POST", "http://localhost:3000/items", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(httptest.NewRecorder(), req)
+
+	started := <-ch
+	expect(t, started.Kind, RequestStarted)
+	expect(t, started.Method, "POST")
+	expect(t, started.Path, "/items")
+
+	finished := <-ch
+	expect(t, finished.Kind, RequestFinished)
+	expect(t, finished.Status, http.StatusCreated)
+
+	events.Unsubscribe(ch)
+	_, open := <-ch
+	expect(t, open, false)
+}
+
+func TestRequestEventsDropsWhenFull(t *testing.T) {
+	events := NewRequestEvents()
+	ch := events.Subscribe(1)
+
+	n := New()
+	n.Use(events)
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(httptest.NewRecorder(), req)
+
+	expect(t, len(ch), 1)
+	expect(t, events.Dropped(), uint64(1))
+}
@@ -0,0 +1,47 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+)
+
+// RequestIDKey is the context key under which RequestID stores the request ID.
+type RequestIDKey struct{}
+
+// RequestIDFromContext returns the ID of the request ctx belongs to, as set by RequestID or
+// RequestAttributer, or "" if there is none.
+func RequestIDFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(RequestIDKey{}).(string); ok {
+		return id
+	}
+	if attrs := AttributesFromContext(ctx); attrs != nil {
+		return attrs.RequestID()
+	}
+	return ""
+}
+
+// RequestID is a middleware handler that gives every request a stable identifier for tracing.
+// The ID is taken from the Header request header, or generated as 32 random hex characters
+// if absent, stored in the context for RequestIDFromContext and echoed on the response.
+type RequestID struct {
+	// Header is the request and response header carrying the ID.
+	Header string
+}
+
+// NewRequestID returns a new instance of RequestID using the X-Request-ID header
+func NewRequestID() *RequestID {
+	return &RequestID{Header: "X-Request-ID"}
+}
+
+func (rid *RequestID) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	id := r.Header.Get(rid.Header)
+	if id == "" {
+		id = RequestIDFromContext(r.Context())
+	}
+	if id == "" {
+		id, _ = randomHex(16)
+	}
+	rw.Header().Set(rid.Header, id)
+
+	next(rw, r.WithContext(context.WithValue(r.Context(), RequestIDKey{}, id)))
+}
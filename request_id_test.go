@@ -0,0 +1,38 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	var id string
+
+	n := New()
+	n.Use(NewRequestID())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		id = RequestIDFromContext(r.Context())
+	})
+
+	recorder := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("X-Request-ID", "abc-123")
+	n.ServeHTTP(recorder, req)
+	expect(t, id, "abc-123")
+	expect(t, recorder.Header().Get("X-Request-ID"), "abc-123")
+
+	recorder = httptest.NewRecorder()
+	req.Header.Del("X-Request-ID")
+	n.ServeHTTP(recorder, req)
+	expect(t, len(id), 32)
+	expect(t, recorder.Header().Get("X-Request-ID"), id)
+}
+
+func TestRequestIDFromContextEmpty(t *testing.T) {
+	expect(t, RequestIDFromContext(context.Background()), "")
+}
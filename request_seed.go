@@ -0,0 +1,46 @@
+package negroni
+
+import (
+	"context"
+	"hash/fnv"
+	"net/http"
+)
+
+type seedKey struct{}
+
+// SeedFromID derives a stable seed from a request ID. Services sharing the request ID derive
+// the same seed, so sampling and bucketing decisions agree across them.
+func SeedFromID(id string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(id))
+	return h.Sum64()
+}
+
+// Seed returns the seed stored by RequestSeed, or 0 if there is none.
+func Seed(ctx context.Context) uint64 {
+	seed, _ := ctx.Value(seedKey{}).(uint64)
+	return seed
+}
+
+// RequestSeed is a middleware handler that derives a seed for sampling and feature bucketing
+// from the request ID and stores it in the context for Seed. The request ID is taken from
+// RequestID or RequestAttributes, falling back to the X-Request-ID header; requests without
+// one get a random seed.
+type RequestSeed struct{}
+
+// NewRequestSeed returns a new instance of RequestSeed
+func NewRequestSeed() *RequestSeed {
+	return &RequestSeed{}
+}
+
+func (s *RequestSeed) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	id := RequestIDFromContext(r.Context())
+	if id == "" {
+		id = r.Header.Get("X-Request-ID")
+	}
+	if id == "" {
+		id, _ = randomHex(16)
+	}
+
+	next(rw, r.WithContext(context.WithValue(r.Context(), seedKey{}, SeedFromID(id))))
+}
@@ -0,0 +1,79 @@
+package negroni
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func serveRequestSeed(t *testing.T, id string) uint64 {
+	var seed uint64
+
+	n := New()
+	n.Use(NewRequestSeed())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		seed = Seed(r.Context())
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	if id != "" {
+		req.Header.Set("X-Request-ID", id)
+	}
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	return seed
+}
+
+func TestRequestSeedStable(t *testing.T) {
+	a := serveRequestSeed(t, "abc123")
+	expect(t, a, SeedFromID("abc123"))
+	expect(t, serveRequestSeed(t, "abc123"), a)
+	refute(t, serveRequestSeed(t, "def456"), a)
+}
+
+func TestRequestSeedWithoutID(t *testing.T) {
+	old := RandReader
+	defer func() { RandReader = old }()
+	RandReader = bytes.NewReader(make([]byte, 16))
+
+	expect(t, serveRequestSeed(t, ""), SeedFromID("00000000000000000000000000000000"))
+}
+
+func TestRequestSeedSources(t *testing.T) {
+	serve := func(ctx func(context.Context) context.Context) uint64 {
+		var seed uint64
+
+		n := New()
+		n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			next(rw, r.WithContext(ctx(r.Context())))
+		})
+		n.Use(NewRequestSeed())
+		n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			seed = Seed(r.Context())
+		})
+
+		req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+		if err != nil {
+			t.Error(err)
+		}
+		req.Header.Set("X-Request-ID", "from-header")
+		n.ServeHTTP(httptest.NewRecorder(), req)
+		return seed
+	}
+
+	// The ID set by RequestID wins over the header.
+	seed := serve(func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, RequestIDKey{}, "from-request-id")
+	})
+	expect(t, seed, SeedFromID("from-request-id"))
+
+	// Attributes without an ID do not hide the header.
+	seed = serve(func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, requestAttributesKey{}, &RequestAttributes{})
+	})
+	expect(t, seed, SeedFromID("from-header"))
+}
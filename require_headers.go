@@ -0,0 +1,58 @@
+package negroni
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// RequireHeaders is a middleware handler that rejects requests missing any of a set of
+// required headers with 400 Bad Request, listing the missing headers in the response body.
+// Headers may additionally be required to match a regular expression.
+type RequireHeaders struct {
+	// Headers lists the headers that must be present.
+	Headers []string
+	// Patterns optionally maps a header to a pattern its value must match.
+	Patterns map[string]*regexp.Regexp
+}
+
+// NewRequireHeaders returns a new instance of RequireHeaders requiring the given headers
+func NewRequireHeaders(headers ...string) *RequireHeaders {
+	return &RequireHeaders{
+		Headers:  headers,
+		Patterns: make(map[string]*regexp.Regexp),
+	}
+}
+
+// Match requires header to be present and its value to match re.
+func (rh *RequireHeaders) Match(header string, re *regexp.Regexp) *RequireHeaders {
+	rh.Headers = append(rh.Headers, header)
+	if rh.Patterns == nil {
+		rh.Patterns = make(map[string]*regexp.Regexp)
+	}
+	rh.Patterns[header] = re
+	return rh
+}
+
+func (rh *RequireHeaders) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	var missing, invalid []string
+	for _, h := range rh.Headers {
+		v := r.Header.Get(h)
+		if v == "" {
+			missing = append(missing, h)
+		} else if re, ok := rh.Patterns[h]; ok && !re.MatchString(v) {
+			invalid = append(invalid, h)
+		}
+	}
+
+	if len(missing) > 0 {
+		http.Error(rw, "missing required headers: "+strings.Join(missing, ", "), http.StatusBadRequest)
+		return
+	}
+	if len(invalid) > 0 {
+		http.Error(rw, "invalid headers: "+strings.Join(invalid, ", "), http.StatusBadRequest)
+		return
+	}
+
+	next(rw, r)
+}
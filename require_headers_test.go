@@ -0,0 +1,60 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func serveRequireHeaders(t *testing.T, rh *RequireHeaders, headers map[string]string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	n.Use(rh)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusNoContent)
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	n.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestRequireHeadersAllPresent(t *testing.T) {
+	rh := NewRequireHeaders("X-Tenant", "X-User")
+	recorder := serveRequireHeaders(t, rh, map[string]string{"X-Tenant": "a", "X-User": "b"})
+	expect(t, recorder.Code, http.StatusNoContent)
+}
+
+func TestRequireHeadersSomeMissing(t *testing.T) {
+	rh := NewRequireHeaders("X-Tenant", "X-User", "X-Region")
+	recorder := serveRequireHeaders(t, rh, map[string]string{"X-User": "b"})
+	expect(t, recorder.Code, http.StatusBadRequest)
+	expect(t, recorder.Body.String(), "missing required headers: X-Tenant, X-Region\n")
+}
+
+func TestRequireHeadersPatternMismatch(t *testing.T) {
+	rh := NewRequireHeaders().Match("X-Tenant", regexp.MustCompile(`^[a-z]+$`))
+
+	recorder := serveRequireHeaders(t, rh, map[string]string{"X-Tenant": "Tenant-1"})
+	expect(t, recorder.Code, http.StatusBadRequest)
+	expect(t, recorder.Body.String(), "invalid headers: X-Tenant\n")
+
+	recorder = serveRequireHeaders(t, rh, map[string]string{"X-Tenant": "tenant"})
+	expect(t, recorder.Code, http.StatusNoContent)
+}
+
+func TestRequireHeadersLiteralMatch(t *testing.T) {
+	rh := (&RequireHeaders{}).Match("X-Tenant", regexp.MustCompile(`^[a-z]+$`))
+
+	recorder := serveRequireHeaders(t, rh, map[string]string{"X-Tenant": "Tenant-1"})
+	expect(t, recorder.Code, http.StatusBadRequest)
+}
@@ -0,0 +1,120 @@
+package negroni
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+)
+
+// responseBuffer is a ResponseWriter that holds the response in memory so a middleware can
+// inspect or rewrite it before it is committed to the underlying ResponseWriter. The decision
+// whether to buffer is made once the status is known: when buffer is nil or returns false the
+// response is passed straight through.
+type responseBuffer struct {
+	rw     ResponseWriter
+	header http.Header
+	status int
+	body   bytes.Buffer
+	buffer func(status int, header http.Header) bool
+
+	passthrough bool
+}
+
+func newResponseBuffer(rw ResponseWriter, buffer func(int, http.Header) bool) *responseBuffer {
+	return &responseBuffer{rw: rw, header: make(http.Header), buffer: buffer}
+}
+
+func (b *responseBuffer) Header() http.Header {
+	if b.passthrough {
+		return b.rw.Header()
+	}
+	return b.header
+}
+
+func (b *responseBuffer) WriteHeader(s int) {
+	if b.status != 0 {
+		return
+	}
+	b.status = s
+	if b.buffer != nil && !b.buffer(s, b.header) {
+		b.passthrough = true
+		b.commitHeader()
+	}
+}
+
+func (b *responseBuffer) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.WriteHeader(http.StatusOK)
+	}
+	if b.passthrough {
+		return b.rw.Write(p)
+	}
+	return b.body.Write(p)
+}
+
+func (b *responseBuffer) Status() int {
+	return b.status
+}
+
+func (b *responseBuffer) Written() bool {
+	return b.status != 0
+}
+
+func (b *responseBuffer) Size() int {
+	if b.passthrough {
+		return b.rw.Size()
+	}
+	return b.body.Len()
+}
+
+func (b *responseBuffer) Before(before func(ResponseWriter)) {
+	b.rw.Before(before)
+}
+
+func (b *responseBuffer) Flush() {
+	if b.passthrough {
+		b.rw.Flush()
+	}
+}
+
+func (b *responseBuffer) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return hijack(b.rw)
+}
+
+func (b *responseBuffer) CloseNotify() <-chan bool {
+	return closeNotify(b.rw)
+}
+
+// buffered reports whether the response is being held in memory.
+func (b *responseBuffer) buffered() bool {
+	return !b.passthrough
+}
+
+func (b *responseBuffer) commitHeader() {
+	dst := b.rw.Header()
+	for k, v := range b.header {
+		dst[k] = v
+	}
+	b.rw.WriteHeader(b.status)
+}
+
+// flush commits a buffered response to the underlying ResponseWriter. It does nothing when
+// the response was passed through. When nothing was written only the headers are copied, so
+// they go out with the implicit 200.
+func (b *responseBuffer) flush() {
+	if b.passthrough {
+		return
+	}
+	if b.status == 0 {
+		dst := b.rw.Header()
+		for k, v := range b.header {
+			dst[k] = v
+		}
+		return
+	}
+	b.commitHeader()
+	if b.body.Len() > 0 {
+		b.rw.Write(b.body.Bytes())
+	}
+}
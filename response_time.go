@@ -0,0 +1,30 @@
+package negroni
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ResponseTime is a middleware handler that reports the server processing time to the client
+// in a response header, set just before the response is written. The value is the elapsed time
+// in milliseconds, e.g. "12.345".
+type ResponseTime struct {
+	// Header is the name of the response header.
+	Header string
+}
+
+// NewResponseTime returns a new instance of ResponseTime using the X-Response-Time header
+func NewResponseTime() *ResponseTime {
+	return &ResponseTime{Header: "X-Response-Time"}
+}
+
+func (rt *ResponseTime) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	start := time.Now()
+	rw.(ResponseWriter).Before(func(rw ResponseWriter) {
+		ms := float64(time.Since(start)) / float64(time.Millisecond)
+		rw.Header().Set(rt.Header, strconv.FormatFloat(ms, 'f', 3, 64))
+	})
+
+	next(rw, r)
+}
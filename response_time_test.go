@@ -0,0 +1,45 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestResponseTime(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	n.Use(NewResponseTime())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		time.Sleep(2 * time.Millisecond)
+		rw.Write([]byte("ok"))
+	})
+
+	n.ServeHTTP(recorder, (*http.Request)(nil))
+	ms, err := strconv.ParseFloat(recorder.Header().Get("X-Response-Time"), 64)
+	expect(t, err, nil)
+	if ms < 2 {
+		t.Errorf("Expected at least 2ms, got %v", ms)
+	}
+}
+
+func TestResponseTimeCustomHeader(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	rt := NewResponseTime()
+	rt.Header = "X-Elapsed"
+
+	n := New()
+	n.Use(rt)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusNoContent)
+	})
+
+	n.ServeHTTP(recorder, (*http.Request)(nil))
+	expect(t, recorder.Header().Get("X-Response-Time"), "")
+	_, err := strconv.ParseFloat(recorder.Header().Get("X-Elapsed"), 64)
+	expect(t, err, nil)
+}
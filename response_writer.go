@@ -10,6 +10,16 @@ import (
 // ResponseWriter is a wrapper around http.ResponseWriter that provides extra information about
 // the response. It is recommended that middleware handlers use this construct to wrap a responsewriter
 // if the functionality calls for it.
+//
+// Headers, including trailers declared with the Trailer header and set after the body, are
+// forwarded to the wrapped http.ResponseWriter.
+//
+// The ResponseWriter returned by NewResponseWriter also implements http.Hijacker and
+// http.CloseNotifier, delegating to the wrapped http.ResponseWriter, and so do the writers of
+// middleware that pass the response through, such as Compress and Verbose, so websocket
+// upgrades keep working through them. They are not part of the interface; check for them with
+// a type assertion. Hijack fails if the wrapped writer doesn't support it, and CloseNotify then
+// returns a channel that never receives.
 type ResponseWriter interface {
 	http.ResponseWriter
 	http.Flusher
@@ -71,15 +81,11 @@ func (rw *responseWriter) Before(before func(ResponseWriter)) {
 }
 
 func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
-	hijacker, ok := rw.ResponseWriter.(http.Hijacker)
-	if !ok {
-		return nil, nil, fmt.Errorf("the ResponseWriter doesn't support the Hijacker interface")
-	}
-	return hijacker.Hijack()
+	return hijack(rw.ResponseWriter)
 }
 
 func (rw *responseWriter) CloseNotify() <-chan bool {
-	return rw.ResponseWriter.(http.CloseNotifier).CloseNotify()
+	return closeNotify(rw.ResponseWriter)
 }
 
 func (rw *responseWriter) callBefore() {
@@ -94,3 +100,30 @@ func (rw *responseWriter) Flush() {
 		flusher.Flush()
 	}
 }
+
+// hijack hijacks the connection of w, or fails if w doesn't support it. Writers wrapping
+// another ResponseWriter use it so that websocket upgrades keep working through them.
+func hijack(w http.ResponseWriter) (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("the ResponseWriter doesn't support the Hijacker interface")
+	}
+	return hijacker.Hijack()
+}
+
+// closeNotify returns the close notification channel of w, or a channel that never receives
+// if w doesn't support it.
+func closeNotify(w http.ResponseWriter) <-chan bool {
+	if notifier, ok := w.(http.CloseNotifier); ok {
+		return notifier.CloseNotify()
+	}
+	return nil
+}
+
+// finalStatus returns the status sent to the client, which is 200 when nothing was written.
+func finalStatus(rw ResponseWriter) int {
+	if rw.Status() == 0 {
+		return http.StatusOK
+	}
+	return rw.Status()
+}
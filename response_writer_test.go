@@ -141,6 +141,36 @@ func TestResponseWriterCloseNotify(t *testing.T) {
 	expect(t, closed, true)
 }
 
+func TestResponseWriterCloseNotifyNotOK(t *testing.T) {
+	rw := NewResponseWriter(httptest.NewRecorder())
+	select {
+	case <-rw.(http.CloseNotifier).CloseNotify():
+		t.Error("Expected no close notification")
+	default:
+	}
+}
+
+func TestResponseWriterWrappersHijack(t *testing.T) {
+	for _, wrap := range []func(ResponseWriter) http.ResponseWriter{
+		func(rw ResponseWriter) http.ResponseWriter { return newResponseBuffer(rw, nil) },
+		func(rw ResponseWriter) http.ResponseWriter { return &compressWriter{rw: rw} },
+		func(rw ResponseWriter) http.ResponseWriter { return &verboseWriter{ResponseWriter: rw} },
+		func(rw ResponseWriter) http.ResponseWriter { return &flightWriter{ResponseWriter: rw} },
+	} {
+		hijackable := newHijackableResponse()
+		rw := wrap(NewResponseWriter(hijackable))
+		_, ok := rw.(http.Flusher)
+		expect(t, ok, true)
+		_, ok = rw.(http.CloseNotifier)
+		expect(t, ok, true)
+		hijacker, ok := rw.(http.Hijacker)
+		expect(t, ok, true)
+		_, _, err := hijacker.Hijack()
+		expect(t, err, nil)
+		expect(t, hijackable.Hijacked, true)
+	}
+}
+
 func TestResponseWriterFlusher(t *testing.T) {
 	rec := httptest.NewRecorder()
 	rw := NewResponseWriter(rec)
@@ -148,3 +178,18 @@ func TestResponseWriterFlusher(t *testing.T) {
 	_, ok := rw.(http.Flusher)
 	expect(t, ok, true)
 }
+
+func TestResponseWriterTrailers(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rw := NewResponseWriter(rec)
+
+	rw.Header().Set("Trailer", "X-Checksum")
+	rw.WriteHeader(http.StatusOK)
+	rw.Write([]byte("Hello world"))
+	rw.Header().Set("X-Checksum", "abc123")
+	rw.Header().Set(http.TrailerPrefix+"X-Undeclared", "late")
+
+	res := rec.Result()
+	expect(t, res.Trailer.Get("X-Checksum"), "abc123")
+	expect(t, res.Trailer.Get("X-Undeclared"), "late")
+}
@@ -0,0 +1,74 @@
+package negroni
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// RewriteRule rewrites request paths matching Pattern to Replacement. The replacement may
+// reference capture groups as $1 or ${name} and may carry its own query string, which is
+// merged with the original query.
+type RewriteRule struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+	// Last stops processing of further rules when this rule matches.
+	Last bool
+}
+
+// Rewrite is a middleware handler that rewrites the request URL to a canonical internal form
+// using ordered regular expression rules, similar to mod_rewrite. Rules are applied in order,
+// each to the result of the previous one. The original query string is preserved.
+type Rewrite struct {
+	Rules []RewriteRule
+}
+
+// NewRewrite returns a new instance of Rewrite with the given rules
+func NewRewrite(rules ...RewriteRule) *Rewrite {
+	return &Rewrite{Rules: rules}
+}
+
+func (rewrite *Rewrite) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	path := r.URL.Path
+	query := r.URL.RawQuery
+	matched := false
+
+	for _, rule := range rewrite.Rules {
+		if !rule.Pattern.MatchString(path) {
+			continue
+		}
+		matched = true
+		path = rule.Pattern.ReplaceAllString(path, rule.Replacement)
+		if i := strings.Index(path, "?"); i >= 0 {
+			// The original query is kept byte for byte, as it may be signed; the rule's
+			// parameters are appended to it.
+			if extra := path[i+1:]; extra != "" {
+				if query != "" {
+					query += "&"
+				}
+				query += extra
+			}
+			path = path[:i]
+		}
+		if rule.Last {
+			break
+		}
+	}
+
+	if !matched {
+		next(rw, r)
+		return
+	}
+
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+	r2.URL.Path = path
+	r2.URL.RawPath = ""
+	r2.URL.RawQuery = query
+	r2.RequestURI = r2.URL.RequestURI()
+
+	next(rw, r2)
+}
@@ -0,0 +1,55 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func serveRewrite(t *testing.T, target string, rules ...RewriteRule) string {
+	var got string
+
+	n := New()
+	n.Use(NewRewrite(rules...))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		got = r.URL.RequestURI()
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000"+target, nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	return got
+}
+
+func TestRewriteBackreference(t *testing.T) {
+	got := serveRewrite(t, "/legacy/users/42?fields=name",
+		RewriteRule{Pattern: regexp.MustCompile(`^/legacy/users/(\d+)$`), Replacement: "/api/v2/users/$1?src=legacy"},
+	)
+	expect(t, got, "/api/v2/users/42?fields=name&src=legacy")
+}
+
+func TestRewriteContinueAndLast(t *testing.T) {
+	rules := []RewriteRule{
+		{Pattern: regexp.MustCompile(`^/old/`), Replacement: "/new/"},
+		{Pattern: regexp.MustCompile(`^/new/(.*)$`), Replacement: "/v2/$1", Last: true},
+		{Pattern: regexp.MustCompile(`^/v2/`), Replacement: "/never/"},
+	}
+	expect(t, serveRewrite(t, "/old/thing", rules...), "/v2/thing")
+}
+
+func TestRewritePassthrough(t *testing.T) {
+	got := serveRewrite(t, "/api/users?x=1",
+		RewriteRule{Pattern: regexp.MustCompile(`^/legacy/`), Replacement: "/"},
+	)
+	expect(t, got, "/api/users?x=1")
+}
+
+func TestRewriteKeepsRawQuery(t *testing.T) {
+	got := serveRewrite(t, "/old/thing?z=1&a=%2f&sig=abc",
+		RewriteRule{Pattern: regexp.MustCompile(`^/old/`), Replacement: "/new/"},
+	)
+	expect(t, got, "/new/thing?z=1&a=%2f&sig=abc")
+}
@@ -0,0 +1,50 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+type routeTimeoutKey struct{}
+
+// WithRouteTimeout returns a copy of ctx carrying a per-route timeout for the RouteTimeout
+// middleware. Router adapters call it before the RouteTimeout middleware runs.
+func WithRouteTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, routeTimeoutKey{}, d)
+}
+
+// RouteTimeoutFromContext returns the per-route timeout stored in ctx, if any.
+func RouteTimeoutFromContext(ctx context.Context) (time.Duration, bool) {
+	d, ok := ctx.Value(routeTimeoutKey{}).(time.Duration)
+	return d, ok
+}
+
+// RouteTimeout is a middleware handler that applies a deadline to the request context. The
+// timeout is read from the context (see WithRouteTimeout) so each route can have its own, and
+// falls back to Default when the route did not configure one.
+type RouteTimeout struct {
+	// Default is the timeout used when none is present in the context. Zero means no deadline.
+	Default time.Duration
+}
+
+// NewRouteTimeout returns a new instance of RouteTimeout with the given default timeout
+func NewRouteTimeout(d time.Duration) *RouteTimeout {
+	return &RouteTimeout{Default: d}
+}
+
+func (rt *RouteTimeout) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	d, ok := RouteTimeoutFromContext(r.Context())
+	if !ok {
+		d = rt.Default
+	}
+	if d <= 0 {
+		next(rw, r)
+		return
+	}
+
+	ctx, cancel := WithMaxDeadline(r.Context(), d)
+	defer cancel()
+
+	next(rw, r.WithContext(ctx))
+}
@@ -0,0 +1,66 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func routeTimeoutDeadline(t *testing.T, route time.Duration) (time.Duration, bool) {
+	var remaining time.Duration
+	var hasDeadline bool
+
+	n := New()
+	if route > 0 {
+		n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			next(rw, r.WithContext(WithRouteTimeout(r.Context(), route)))
+		})
+	}
+	n.Use(NewRouteTimeout(time.Hour))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var deadline time.Time
+		deadline, hasDeadline = r.Context().Deadline()
+		remaining = time.Until(deadline)
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	return remaining, hasDeadline
+}
+
+func TestRouteTimeoutFromContext(t *testing.T) {
+	remaining, ok := routeTimeoutDeadline(t, 50*time.Millisecond)
+	expect(t, ok, true)
+	if remaining > 50*time.Millisecond {
+		t.Errorf("Expected route deadline of at most 50ms, got %v", remaining)
+	}
+}
+
+func TestRouteTimeoutDefault(t *testing.T) {
+	remaining, ok := routeTimeoutDeadline(t, 0)
+	expect(t, ok, true)
+	if remaining < 59*time.Minute {
+		t.Errorf("Expected default deadline of about an hour, got %v", remaining)
+	}
+}
+
+func TestRouteTimeoutZeroDefault(t *testing.T) {
+	hasDeadline := true
+
+	n := New()
+	n.Use(NewRouteTimeout(0))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_, hasDeadline = r.Context().Deadline()
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, hasDeadline, false)
+}
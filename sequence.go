@@ -0,0 +1,79 @@
+package negroni
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// SequenceStore records the last sequence number seen per client.
+type SequenceStore interface {
+	// Advance records seq as the last sequence number of client if it is greater than the
+	// one recorded before, or if none was. It returns whether seq was recorded and the last
+	// sequence number of client afterwards.
+	Advance(client string, seq uint64) (ok bool, last uint64)
+}
+
+// MemorySequenceStore is an in-memory SequenceStore safe for concurrent use. It keeps one
+// number per client and never forgets a client.
+type MemorySequenceStore struct {
+	mu   sync.Mutex
+	last map[string]uint64
+}
+
+// NewMemorySequenceStore returns a new, empty MemorySequenceStore
+func NewMemorySequenceStore() *MemorySequenceStore {
+	return &MemorySequenceStore{last: make(map[string]uint64)}
+}
+
+func (s *MemorySequenceStore) Advance(client string, seq uint64) (bool, uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if last, ok := s.last[client]; ok && seq <= last {
+		return false, last
+	}
+	s.last[client] = seq
+	return true, seq
+}
+
+// Sequence is a middleware handler that enforces increasing sequence numbers per client, for
+// ordered command APIs over HTTP. Every request must carry its sequence number in Header and
+// it must be greater than that of the client's previous request; gaps are allowed. Requests
+// without a valid number are rejected with 400 Bad Request, and out-of-order or replayed
+// requests with 409 Conflict. A number is used up once the request is let through, even if
+// the handler then fails.
+type Sequence struct {
+	// Header is the request header carrying the sequence number.
+	Header string
+	// Client returns the key sequence numbers are tracked by. It defaults to the client IP.
+	Client func(r *http.Request) string
+	Store  SequenceStore
+}
+
+// NewSequence returns a new instance of Sequence reading the X-Sequence header and tracking
+// numbers per client IP in memory
+func NewSequence() *Sequence {
+	return &Sequence{
+		Header: "X-Sequence",
+		Client: func(r *http.Request) string {
+			return clientIP(r, false)
+		},
+		Store: NewMemorySequenceStore(),
+	}
+}
+
+func (s *Sequence) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	seq, err := strconv.ParseUint(r.Header.Get(s.Header), 10, 64)
+	if err != nil {
+		http.Error(rw, "missing or invalid "+s.Header+" header", http.StatusBadRequest)
+		return
+	}
+	if ok, last := s.Store.Advance(s.Client(r), seq); !ok {
+		http.Error(rw, "sequence number "+strconv.FormatUint(seq, 10)+" is not after "+
+			strconv.FormatUint(last, 10), http.StatusConflict)
+		return
+	}
+
+	next(rw, r)
+}
@@ -0,0 +1,53 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSequence(t *testing.T) {
+	served := 0
+
+	n := New()
+	n.Use(NewSequence())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		served++
+	})
+
+	send := func(client, seq string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("POST", "http://localhost:3000/commands", nil)
+		if err != nil {
+			t.Error(err)
+		}
+		req.RemoteAddr = client + ":1234"
+		if seq != "" {
+			req.Header.Set("X-Sequence", seq)
+		}
+		recorder := httptest.NewRecorder()
+		n.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// In order, gaps allowed, and tracked separately per client.
+	expect(t, send("10.0.0.1", "1").Code, http.StatusOK)
+	expect(t, send("10.0.0.1", "2").Code, http.StatusOK)
+	expect(t, send("10.0.0.1", "5").Code, http.StatusOK)
+	expect(t, send("10.0.0.2", "1").Code, http.StatusOK)
+	expect(t, served, 4)
+
+	// Replayed and out of order.
+	replay := send("10.0.0.1", "5")
+	expect(t, replay.Code, http.StatusConflict)
+	expect(t, strings.TrimSpace(replay.Body.String()), "sequence number 5 is not after 5")
+	expect(t, send("10.0.0.1", "3").Code, http.StatusConflict)
+
+	// Missing or invalid.
+	expect(t, send("10.0.0.1", "").Code, http.StatusBadRequest)
+	expect(t, send("10.0.0.1", "-1").Code, http.StatusBadRequest)
+	expect(t, served, 4)
+
+	expect(t, send("10.0.0.1", "6").Code, http.StatusOK)
+	expect(t, served, 5)
+}
@@ -0,0 +1,41 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func serveRange(t *testing.T, rangeHeader string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "text/plain")
+		ServeRange(rw, r, time.Time{}, strings.NewReader("0123456789"))
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/blob", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("Range", rangeHeader)
+
+	n.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestServeRangeValid(t *testing.T) {
+	recorder := serveRange(t, "bytes=2-5")
+	expect(t, recorder.Code, http.StatusPartialContent)
+	expect(t, recorder.Header().Get("Content-Range"), "bytes 2-5/10")
+	expect(t, recorder.Body.String(), "2345")
+}
+
+func TestServeRangeUnsatisfiable(t *testing.T) {
+	recorder := serveRange(t, "bytes=20-30")
+	expect(t, recorder.Code, http.StatusRequestedRangeNotSatisfiable)
+	expect(t, recorder.Header().Get("Content-Range"), "bytes */10")
+}
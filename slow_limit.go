@@ -0,0 +1,70 @@
+package negroni
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// SlowLimit is a middleware handler that caps how many slow requests run at the same time.
+// A request key (the URL path by default) becomes slow once a request to it takes longer
+// than Threshold, and fast again once one completes within it. When Max requests to slow
+// keys are already running, further requests to slow keys are shed with 503 so they cannot
+// starve the fast endpoints.
+type SlowLimit struct {
+	// Threshold is the latency above which a request is considered slow.
+	Threshold time.Duration
+	// Max is the number of requests to slow keys allowed to run concurrently.
+	Max int
+	// Key groups requests; it defaults to the URL path.
+	Key func(r *http.Request) string
+
+	mu      sync.Mutex
+	slow    map[string]bool
+	running int
+}
+
+// NewSlowLimit returns a new instance of SlowLimit
+func NewSlowLimit(threshold time.Duration, max int) *SlowLimit {
+	return &SlowLimit{
+		Threshold: threshold,
+		Max:       max,
+		Key:       func(r *http.Request) string { return r.URL.Path },
+	}
+}
+
+func (s *SlowLimit) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	key := s.Key(r)
+
+	s.mu.Lock()
+	slow := s.slow[key]
+	if slow {
+		if s.running >= s.Max {
+			s.mu.Unlock()
+			http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+		s.running++
+	}
+	s.mu.Unlock()
+
+	start := time.Now()
+	defer func() {
+		elapsed := time.Since(start)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if slow {
+			s.running--
+		}
+		if elapsed > s.Threshold {
+			if s.slow == nil {
+				s.slow = make(map[string]bool)
+			}
+			s.slow[key] = true
+		} else {
+			delete(s.slow, key)
+		}
+	}()
+
+	next(rw, r)
+}
@@ -0,0 +1,54 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSlowLimitShedsSlowRequests(t *testing.T) {
+	release := make(chan struct{})
+	entered := make(chan struct{}, 1)
+	first := true
+
+	n := New()
+	n.Use(NewSlowLimit(20*time.Millisecond, 1))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/report" {
+			return
+		}
+		if first {
+			first = false
+			time.Sleep(30 * time.Millisecond)
+			return
+		}
+		entered <- struct{}{}
+		<-release
+	})
+
+	serve := func(path string) int {
+		recorder := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "http://localhost:3000"+path, nil)
+		if err != nil {
+			t.Error(err)
+		}
+		n.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	// The first request marks /report as slow.
+	expect(t, serve("/report"), http.StatusOK)
+
+	// A second one occupies the only slow slot.
+	done := make(chan int)
+	go func() { done <- serve("/report") }()
+	<-entered
+
+	// Further slow requests are shed while fast ones still pass.
+	expect(t, serve("/report"), http.StatusServiceUnavailable)
+	expect(t, serve("/health"), http.StatusOK)
+
+	close(release)
+	expect(t, <-done, http.StatusOK)
+}
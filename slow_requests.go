@@ -0,0 +1,90 @@
+package negroni
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SlowRequest describes a request recorded by SlowRequests.
+type SlowRequest struct {
+	Method   string
+	Path     string
+	Status   int
+	Duration time.Duration
+	Time     time.Time
+}
+
+// SlowRequests is a middleware handler that keeps the N slowest recent requests in memory to
+// help find latency outliers while debugging. Requests older than MaxAge are forgotten so the
+// list reflects recent traffic.
+type SlowRequests struct {
+	// N is the number of requests kept.
+	N int
+	// MaxAge is how long a request is kept. Zero keeps requests until slower ones displace them.
+	MaxAge time.Duration
+
+	mu       sync.Mutex
+	requests []SlowRequest
+}
+
+// NewSlowRequests returns a new instance of SlowRequests keeping the n slowest requests of the last hour
+func NewSlowRequests(n int) *SlowRequests {
+	return &SlowRequests{N: n, MaxAge: time.Hour}
+}
+
+func (s *SlowRequests) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	start := time.Now()
+
+	next(rw, r)
+
+	s.record(SlowRequest{
+		Method:   r.Method,
+		Path:     r.URL.Path,
+		Status:   finalStatus(rw.(ResponseWriter)),
+		Duration: time.Since(start),
+		Time:     start,
+	})
+}
+
+// Slowest returns the recorded requests, slowest first.
+func (s *SlowRequests) Slowest() []SlowRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire(time.Now())
+	return append([]SlowRequest(nil), s.requests...)
+}
+
+func (s *SlowRequests) record(req SlowRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire(req.Time)
+
+	if len(s.requests) >= s.N {
+		if s.N <= 0 || req.Duration <= s.requests[len(s.requests)-1].Duration {
+			return
+		}
+		s.requests = s.requests[:len(s.requests)-1]
+	}
+
+	i := sort.Search(len(s.requests), func(i int) bool {
+		return s.requests[i].Duration < req.Duration
+	})
+	s.requests = append(s.requests, SlowRequest{})
+	copy(s.requests[i+1:], s.requests[i:])
+	s.requests[i] = req
+}
+
+func (s *SlowRequests) expire(now time.Time) {
+	if s.MaxAge <= 0 {
+		return
+	}
+	kept := s.requests[:0]
+	for _, req := range s.requests {
+		if now.Sub(req.Time) <= s.MaxAge {
+			kept = append(kept, req)
+		}
+	}
+	s.requests = kept
+}
@@ -0,0 +1,47 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSlowRequests(t *testing.T) {
+	s := NewSlowRequests(2)
+
+	n := New()
+	n.Use(s)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		d, _ := time.ParseDuration(r.URL.Query().Get("sleep"))
+		time.Sleep(d)
+	})
+
+	for _, sleep := range []string{"1ms", "20ms", "5ms", "10ms", "2ms"} {
+		req, err := http.NewRequest("GET", "http://localhost:3000/"+sleep+"?sleep="+sleep, nil)
+		if err != nil {
+			t.Error(err)
+		}
+		n.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	slowest := s.Slowest()
+	expect(t, len(slowest), 2)
+	expect(t, slowest[0].Path, "/20ms")
+	expect(t, slowest[1].Path, "/10ms")
+	expect(t, slowest[0].Method, "GET")
+	expect(t, slowest[0].Status, http.StatusOK)
+}
+
+func TestSlowRequestsMaxAge(t *testing.T) {
+	s := NewSlowRequests(2)
+	s.MaxAge = time.Minute
+
+	now := time.Now()
+	s.record(SlowRequest{Path: "/old", Duration: time.Second, Time: now.Add(-2 * time.Minute)})
+	s.record(SlowRequest{Path: "/new", Duration: time.Millisecond, Time: now})
+
+	slowest := s.Slowest()
+	expect(t, len(slowest), 1)
+	expect(t, slowest[0].Path, "/new")
+}
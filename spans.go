@@ -0,0 +1,106 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type spansKey struct{}
+
+// Span is the total time a request spent in a named sub-operation, such as "db" or "render".
+type Span struct {
+	Name     string
+	Duration time.Duration
+}
+
+type spans struct {
+	mu    sync.Mutex
+	spans []Span
+}
+
+// withSpans returns ctx with somewhere to record spans, keeping the one already there if any
+// so that Logger and ServerTiming see the same spans.
+func withSpans(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(spansKey{}).(*spans); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, spansKey{}, &spans{})
+}
+
+// StartSpan starts timing a sub-operation of the request ctx belongs to. Calling the returned
+// function stops the timer and adds the elapsed time to the span called name, so a span
+// started several times accumulates, e.g.
+//
+//	done := negroni.StartSpan(r.Context(), "db")
+//	rows, err := db.Query(...)
+//	done()
+//
+// Spans are reported by Logger and ServerTiming. StartSpan does nothing unless one of them is
+// in the stack.
+func StartSpan(ctx context.Context, name string) func() {
+	s, ok := ctx.Value(spansKey{}).(*spans)
+	if !ok {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		s.add(name, time.Since(start))
+	}
+}
+
+// Spans returns the spans recorded for the request ctx belongs to, in the order they were
+// first started.
+func Spans(ctx context.Context) []Span {
+	s, ok := ctx.Value(spansKey{}).(*spans)
+	if !ok {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Span(nil), s.spans...)
+}
+
+func (s *spans) add(name string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.spans {
+		if s.spans[i].Name == name {
+			s.spans[i].Duration += d
+			return
+		}
+	}
+	s.spans = append(s.spans, Span{name, d})
+}
+
+// ServerTiming is a middleware handler that reports the spans recorded with StartSpan to the
+// client in a Server-Timing response header, e.g. "db;dur=12.345, render;dur=3.100". The
+// header is set just before the response is written, so only spans finished by then are
+// included.
+type ServerTiming struct{}
+
+// NewServerTiming returns a new instance of ServerTiming
+func NewServerTiming() *ServerTiming {
+	return &ServerTiming{}
+}
+
+func (st *ServerTiming) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	ctx := withSpans(r.Context())
+	rw.(ResponseWriter).Before(func(rw ResponseWriter) {
+		recorded := Spans(ctx)
+		if len(recorded) == 0 {
+			return
+		}
+		metrics := make([]string, len(recorded))
+		for i, s := range recorded {
+			ms := float64(s.Duration) / float64(time.Millisecond)
+			metrics[i] = s.Name + ";dur=" + strconv.FormatFloat(ms, 'f', 3, 64)
+		}
+		rw.Header().Add("Server-Timing", strings.Join(metrics, ", "))
+	})
+
+	next(rw, r.WithContext(ctx))
+}
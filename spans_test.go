@@ -0,0 +1,97 @@
+package negroni
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStartSpan(t *testing.T) {
+	StartSpan(context.Background(), "ignored")()
+	expect(t, len(Spans(context.Background())), 0)
+
+	ctx := withSpans(context.Background())
+	done := StartSpan(ctx, "db")
+	time.Sleep(time.Millisecond)
+	done()
+	StartSpan(ctx, "cache")()
+	StartSpan(ctx, "db")()
+
+	spans := Spans(ctx)
+	expect(t, len(spans), 2)
+	expect(t, spans[0].Name, "db")
+	expect(t, spans[1].Name, "cache")
+	if spans[0].Duration < time.Millisecond {
+		t.Errorf("Expected db span of at least 1ms, got %v", spans[0].Duration)
+	}
+}
+
+func TestLoggerSpans(t *testing.T) {
+	buff := bytes.NewBufferString("")
+
+	l := NewLogger()
+	l.Logger = log.New(buff, "", 0)
+
+	n := New()
+	n.Use(l)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		StartSpan(r.Context(), "db")()
+		StartSpan(r.Context(), "render")()
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/orders", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	lines := strings.Split(strings.TrimSpace(buff.String()), "\n")
+	expect(t, len(lines), 2)
+	if !strings.Contains(lines[1], ") db=") || !strings.Contains(lines[1], " render=") {
+		t.Errorf("Expected db and render spans in %q", lines[1])
+	}
+}
+
+func TestServerTiming(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	n.Use(NewServerTiming())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		StartSpan(r.Context(), "db")()
+		StartSpan(r.Context(), "cache")()
+		rw.Write([]byte("ok"))
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/orders", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(recorder, req)
+	metrics := strings.Split(recorder.Header().Get("Server-Timing"), ", ")
+	expect(t, len(metrics), 2)
+	expect(t, strings.HasPrefix(metrics[0], "db;dur="), true)
+	expect(t, strings.HasPrefix(metrics[1], "cache;dur="), true)
+}
+
+func TestServerTimingWithoutSpans(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	n.Use(NewServerTiming())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte("ok"))
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(recorder, req)
+	_, ok := recorder.Header()["Server-Timing"]
+	expect(t, ok, false)
+}
@@ -1,6 +1,7 @@
 package negroni
 
 import (
+	"net"
 	"net/http"
 	"path"
 	"strings"
@@ -14,6 +15,9 @@ type Static struct {
 	Prefix string
 	// IndexFile defines which file to serve as index if it exists.
 	IndexFile string
+	// Hosts optionally maps a request host to the filesystem to serve it from. Hosts not in
+	// the map are served from Dir.
+	Hosts map[string]http.FileSystem
 }
 
 // NewStatic returns a new instance of Static
@@ -25,6 +29,14 @@ func NewStatic(directory http.FileSystem) *Static {
 	}
 }
 
+// NewHostStatic returns a new instance of Static that serves each host in hosts from its own
+// filesystem, e.g. "site-a.com" from http.Dir("public/a"), and unknown hosts from fallback
+func NewHostStatic(hosts map[string]http.FileSystem, fallback http.FileSystem) *Static {
+	s := NewStatic(fallback)
+	s.Hosts = hosts
+	return s
+}
+
 func (s *Static) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
 	if r.Method != "GET" && r.Method != "HEAD" {
 		next(rw, r)
@@ -43,7 +55,8 @@ func (s *Static) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.Ha
 			return
 		}
 	}
-	f, err := s.Dir.Open(file)
+	dir := s.dir(r)
+	f, err := dir.Open(file)
 	if err != nil {
 		// discard the error?
 		next(rw, r)
@@ -66,7 +79,7 @@ func (s *Static) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.Ha
 		}
 
 		file = path.Join(file, s.IndexFile)
-		f, err = s.Dir.Open(file)
+		f, err = dir.Open(file)
 		if err != nil {
 			next(rw, r)
 			return
@@ -82,3 +95,28 @@ func (s *Static) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.Ha
 
 	http.ServeContent(rw, r, file, fi.ModTime(), f)
 }
+
+// dir returns the filesystem to serve r from.
+func (s *Static) dir(r *http.Request) http.FileSystem {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if d, ok := s.Hosts[strings.ToLower(host)]; ok {
+		return d
+	}
+	return s.Dir
+}
+
+// Describe summarizes the Static configuration for Negroni.DescribeJSON.
+func (s *Static) Describe() HandlerDescriptor {
+	return HandlerDescriptor{Config: map[string]interface{}{
+		"prefix":     s.Prefix,
+		"index_file": s.IndexFile,
+	}}
+}
+
+// Role identifies Static to Negroni.Validate.
+func (s *Static) Role() HandlerRole {
+	return RoleStatic
+}
@@ -111,3 +111,43 @@ func TestStaticOptionsPrefix(t *testing.T) {
 	n.ServeHTTP(response, req)
 	expect(t, response.Code, http.StatusOK)
 }
+
+func TestHostStatic(t *testing.T) {
+	n := New()
+	n.Use(NewHostStatic(map[string]http.FileSystem{
+		"site-a.com": http.Dir("testdata/sites/a"),
+		"site-b.com": http.Dir("testdata/sites/b"),
+	}, http.Dir(".")))
+	n.UseHandler(http.NotFoundHandler())
+
+	for host, body := range map[string]string{
+		"site-a.com":      "site a\n",
+		"site-b.com:3000": "site b\n",
+	} {
+		response := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "http://"+host+"/hello.txt", nil)
+		if err != nil {
+			t.Error(err)
+		}
+		n.ServeHTTP(response, req)
+		expect(t, response.Code, http.StatusOK)
+		expect(t, response.Body.String(), body)
+	}
+
+	// Unknown hosts fall back to the default root.
+	response := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "http://localhost:3000/hello.txt", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(response, req)
+	expect(t, response.Code, http.StatusNotFound)
+
+	response = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "http://localhost:3000/negroni.go", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(response, req)
+	expect(t, response.Code, http.StatusOK)
+}
@@ -0,0 +1,81 @@
+package negroni
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// StrictQuery is a middleware handler that only accepts query parameters from an allowlist, to
+// catch client typos and keep API contracts tight. Requests with unknown parameters are
+// rejected with 400 Bad Request listing them, or, when Strip is set, passed on with the
+// unknown parameters removed.
+type StrictQuery struct {
+	// Allowed lists the accepted query parameters.
+	Allowed []string
+	// Strip removes unknown parameters instead of rejecting the request.
+	Strip bool
+}
+
+// NewStrictQuery returns a new instance of StrictQuery accepting the given query parameters
+func NewStrictQuery(allowed ...string) *StrictQuery {
+	return &StrictQuery{Allowed: allowed}
+}
+
+func (sq *StrictQuery) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if r.URL.RawQuery == "" {
+		next(rw, r)
+		return
+	}
+
+	var kept, unknown []string
+	for _, pair := range strings.Split(r.URL.RawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		rawKey := pair
+		if i := strings.Index(pair, "="); i >= 0 {
+			rawKey = pair[:i]
+		}
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			key = rawKey
+		}
+		if sq.allowed(key) {
+			kept = append(kept, pair)
+		} else if !containsString(unknown, key) {
+			unknown = append(unknown, key)
+		}
+	}
+
+	if len(unknown) == 0 {
+		next(rw, r)
+		return
+	}
+	if !sq.Strip {
+		http.Error(rw, "unknown query parameters: "+strings.Join(unknown, ", "), http.StatusBadRequest)
+		return
+	}
+
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+	r2.URL.RawQuery = strings.Join(kept, "&")
+	r2.RequestURI = r2.URL.RequestURI()
+
+	next(rw, r2)
+}
+
+func (sq *StrictQuery) allowed(key string) bool {
+	return containsString(sq.Allowed, key)
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
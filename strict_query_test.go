@@ -0,0 +1,46 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func serveStrictQuery(t *testing.T, sq *StrictQuery, url string) (*httptest.ResponseRecorder, string) {
+	var query string
+
+	n := New()
+	n.Use(sq)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+	})
+
+	recorder := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(recorder, req)
+	return recorder, query
+}
+
+func TestStrictQueryReject(t *testing.T) {
+	sq := NewStrictQuery("page", "limit")
+
+	recorder, query := serveStrictQuery(t, sq, "http://localhost:3000/items?page=2&limit=10")
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, query, "page=2&limit=10")
+
+	recorder, _ = serveStrictQuery(t, sq, "http://localhost:3000/items?page=2&limt=10&sort=asc&limt=5")
+	expect(t, recorder.Code, http.StatusBadRequest)
+	expect(t, recorder.Body.String(), "unknown query parameters: limt, sort\n")
+}
+
+func TestStrictQueryStrip(t *testing.T) {
+	sq := NewStrictQuery("page", "limit")
+	sq.Strip = true
+
+	recorder, query := serveStrictQuery(t, sq, "http://localhost:3000/items?debug=1&page=2&limit=10")
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, query, "page=2&limit=10")
+}
@@ -0,0 +1,82 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+)
+
+type tenantKey struct{}
+
+// WithTenant returns a copy of ctx carrying the tenant identifier of the request. It is
+// usually called by authentication middleware.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant identifier stored with WithTenant, if any.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantKey{}).(string)
+	return tenant, ok
+}
+
+// QuotaStore tracks the remaining usage quota of tenants.
+type QuotaStore interface {
+	// Take atomically consumes one request from the tenant's quota, reporting false if the
+	// quota was already exhausted.
+	Take(tenant string) (ok bool, err error)
+}
+
+// QuotaRefunder is implemented by a QuotaStore that can give back a unit taken with Take.
+type QuotaRefunder interface {
+	Refund(tenant string) error
+}
+
+// TenantQuota is a middleware handler that enforces per-tenant usage quotas for multi-tenant
+// services. The tenant is read from the context (see WithTenant); requests from tenants whose
+// quota is exhausted are rejected with ExhaustedStatus. A unit of quota is taken before the
+// request is served, so concurrent requests cannot overrun it; with RefundFailures set and a
+// store implementing QuotaRefunder, it is given back when the response status is 400 or above.
+// Requests without a tenant are passed through.
+type TenantQuota struct {
+	Store QuotaStore
+	// ExhaustedStatus is the status sent when the quota is exhausted, usually 429 Too Many
+	// Requests or 402 Payment Required.
+	ExhaustedStatus int
+	// RefundFailures gives back the quota of requests answered with an error status.
+	RefundFailures bool
+}
+
+// NewTenantQuota returns a new instance of TenantQuota backed by store
+func NewTenantQuota(store QuotaStore) *TenantQuota {
+	return &TenantQuota{
+		Store:           store,
+		ExhaustedStatus: http.StatusTooManyRequests,
+		RefundFailures:  true,
+	}
+}
+
+func (q *TenantQuota) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	tenant, ok := TenantFromContext(r.Context())
+	if !ok {
+		next(rw, r)
+		return
+	}
+
+	ok, err := q.Store.Take(tenant)
+	if err != nil {
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		http.Error(rw, "quota exhausted", q.ExhaustedStatus)
+		return
+	}
+
+	next(rw, r)
+
+	if refunder, ok := q.Store.(QuotaRefunder); ok && q.RefundFailures {
+		if finalStatus(rw.(ResponseWriter)) >= http.StatusBadRequest {
+			refunder.Refund(tenant)
+		}
+	}
+}
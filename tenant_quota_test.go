@@ -0,0 +1,90 @@
+package negroni
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type stubQuotaStore map[string]int
+
+func (s stubQuotaStore) Take(tenant string) (bool, error) {
+	if s[tenant] <= 0 {
+		return false, nil
+	}
+	s[tenant]--
+	return true, nil
+}
+
+func (s stubQuotaStore) Refund(tenant string) error {
+	s[tenant]++
+	return nil
+}
+
+type failingQuotaStore struct{}
+
+func (failingQuotaStore) Take(tenant string) (bool, error) {
+	return false, errors.New("store unavailable")
+}
+
+func serveTenantQuota(t *testing.T, q *TenantQuota, tenant string) int {
+	return serveTenantQuotaStatus(t, q, tenant, http.StatusOK)
+}
+
+func serveTenantQuotaStatus(t *testing.T, q *TenantQuota, tenant string, status int) int {
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		next(rw, r.WithContext(WithTenant(r.Context(), tenant)))
+	})
+	n.Use(q)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(status)
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(recorder, req)
+	return recorder.Code
+}
+
+func TestTenantQuotaUnderQuota(t *testing.T) {
+	store := stubQuotaStore{"acme": 2}
+	q := NewTenantQuota(store)
+
+	expect(t, serveTenantQuota(t, q, "acme"), http.StatusOK)
+	expect(t, store["acme"], 1)
+	expect(t, serveTenantQuota(t, q, "acme"), http.StatusOK)
+	expect(t, store["acme"], 0)
+}
+
+func TestTenantQuotaOverQuota(t *testing.T) {
+	store := stubQuotaStore{"acme": 0}
+	q := NewTenantQuota(store)
+	expect(t, serveTenantQuota(t, q, "acme"), http.StatusTooManyRequests)
+
+	q.ExhaustedStatus = http.StatusPaymentRequired
+	expect(t, serveTenantQuota(t, q, "acme"), http.StatusPaymentRequired)
+	expect(t, store["acme"], 0)
+}
+
+func TestTenantQuotaRefundsFailures(t *testing.T) {
+	store := stubQuotaStore{"acme": 1}
+	q := NewTenantQuota(store)
+
+	expect(t, serveTenantQuotaStatus(t, q, "acme", http.StatusBadGateway), http.StatusBadGateway)
+	expect(t, store["acme"], 1)
+
+	q.RefundFailures = false
+	expect(t, serveTenantQuotaStatus(t, q, "acme", http.StatusBadGateway), http.StatusBadGateway)
+	expect(t, store["acme"], 0)
+}
+
+func TestTenantQuotaStoreError(t *testing.T) {
+	q := NewTenantQuota(failingQuotaStore{})
+	expect(t, serveTenantQuota(t, q, "acme"), http.StatusInternalServerError)
+}
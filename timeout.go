@@ -0,0 +1,182 @@
+package negroni
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Timeout is a middleware handler that bounds the time spent in the rest of the chain. Unlike
+// http.TimeoutHandler it cancels the request context when the deadline passes and does not
+// buffer the response, so handlers may stream. If the deadline fires before the handler has
+// written anything a 503 is sent; once the response is committed it is left as is and any
+// later writes from the handler fail with http.ErrHandlerTimeout.
+//
+// The handler runs on its own goroutine. A panic there is raised again as a *RecoveredPanic
+// carrying the handler's stack; one that happens after the deadline, when nothing is waiting
+// for the handler any more, is written to Logger.
+type Timeout struct {
+	// Duration is the maximum time allowed for the rest of the chain.
+	Duration time.Duration
+	// Message is written as the body of the 503 response.
+	Message string
+	Logger  *log.Logger
+}
+
+// NewTimeout returns a new instance of Timeout
+func NewTimeout(d time.Duration) *Timeout {
+	return &Timeout{
+		Duration: d,
+		Message:  "request timeout",
+		Logger:   log.New(os.Stdout, "[negroni] ", 0),
+	}
+}
+
+// Describe summarizes the Timeout configuration for Negroni.DescribeJSON.
+func (t *Timeout) Describe() HandlerDescriptor {
+	return HandlerDescriptor{Config: map[string]interface{}{
+		"duration": t.Duration.String(),
+	}}
+}
+
+func (t *Timeout) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	ctx, cancel := WithMaxDeadline(r.Context(), t.Duration)
+	defer cancel()
+
+	tw := &timeoutWriter{ResponseWriter: rw.(ResponseWriter), header: make(http.Header)}
+	done := make(chan struct{})
+	panicked := make(chan *RecoveredPanic, 1)
+
+	go func() {
+		defer func() {
+			if err := recover(); err != nil {
+				p := &RecoveredPanic{Value: err, Stack: debug.Stack()}
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				if tw.timedOut {
+					t.logLatePanic(p)
+					return
+				}
+				panicked <- p
+			}
+		}()
+		next(tw, r.WithContext(ctx))
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		tw.mu.Lock()
+		defer tw.mu.Unlock()
+		if !tw.committed {
+			// The handler returned without writing; its headers go out with the implicit 200.
+			dst := tw.ResponseWriter.Header()
+			for k, v := range tw.header {
+				dst[k] = v
+			}
+			tw.committed = true
+		}
+	case p := <-panicked:
+		panic(p)
+	case <-ctx.Done():
+		tw.mu.Lock()
+		defer tw.mu.Unlock()
+		tw.timedOut = true
+		// The handler may still hold on to the headers; give it a map of its own so it never
+		// touches the ones of the response being sent.
+		tw.header = make(http.Header)
+		select {
+		case p := <-panicked:
+			// Panicked just as the deadline passed.
+			t.logLatePanic(p)
+		default:
+		}
+		if !tw.ResponseWriter.Written() {
+			http.Error(tw.ResponseWriter, t.Message, http.StatusServiceUnavailable)
+		}
+	}
+}
+
+func (t *Timeout) logLatePanic(p *RecoveredPanic) {
+	t.Logger.Printf("PANIC after timeout: %s\n%s", p.Value, p.Stack)
+}
+
+// timeoutWriter guards the underlying ResponseWriter so that the handler goroutine and the
+// Timeout middleware never write to it concurrently. Headers are kept separately until the
+// handler commits the response; after that the underlying headers are used so trailers set
+// after the body reach the client.
+type timeoutWriter struct {
+	ResponseWriter
+	mu        sync.Mutex
+	header    http.Header
+	committed bool
+	timedOut  bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.committed && !tw.timedOut {
+		return tw.ResponseWriter.Header()
+	}
+	return tw.header
+}
+
+func (tw *timeoutWriter) WriteHeader(s int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.ResponseWriter.Written() {
+		return
+	}
+	tw.writeHeader(s)
+}
+
+func (tw *timeoutWriter) writeHeader(s int) {
+	dst := tw.ResponseWriter.Header()
+	for k, v := range tw.header {
+		dst[k] = v
+	}
+	tw.committed = true
+	tw.ResponseWriter.WriteHeader(s)
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !tw.ResponseWriter.Written() {
+		tw.writeHeader(http.StatusOK)
+	}
+	return tw.ResponseWriter.Write(b)
+}
+
+func (tw *timeoutWriter) Flush() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if !tw.timedOut {
+		tw.ResponseWriter.Flush()
+	}
+}
+
+func (tw *timeoutWriter) Status() int {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	return tw.ResponseWriter.Status()
+}
+
+func (tw *timeoutWriter) Written() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	return tw.ResponseWriter.Written()
+}
+
+func (tw *timeoutWriter) Size() int {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	return tw.ResponseWriter.Size()
+}
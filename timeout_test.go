@@ -0,0 +1,220 @@
+package negroni
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeoutFastHandler(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	n.Use(NewTimeout(time.Second))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("X-Fast", "yes")
+		rw.Write([]byte("done"))
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, recorder.Body.String(), "done")
+	expect(t, recorder.Header().Get("X-Fast"), "yes")
+}
+
+func TestTimeoutCancelsContext(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	cancelled := make(chan bool, 1)
+
+	n := New()
+	n.Use(NewTimeout(10 * time.Millisecond))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		cancelled <- true
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusServiceUnavailable)
+	expect(t, <-cancelled, true)
+}
+
+func TestTimeoutAfterCommittedStatus(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	done := make(chan struct{})
+
+	n := New()
+	n.Use(NewTimeout(10 * time.Millisecond))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusAccepted)
+		<-r.Context().Done()
+		close(done)
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(recorder, req)
+	<-done
+	expect(t, recorder.Code, http.StatusAccepted)
+	expect(t, recorder.Body.String(), "")
+}
+
+func TestTimeoutIgnoredCancellation(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	writeErr := make(chan error, 1)
+
+	n := New()
+	n.Use(NewTimeout(10 * time.Millisecond))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		_, err := rw.Write([]byte("too late"))
+		writeErr <- err
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusServiceUnavailable)
+	expect(t, recorder.Body.String(), "request timeout\n")
+	expect(t, <-writeErr, http.ErrHandlerTimeout)
+}
+
+func TestTimeoutPropagatesPanic(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		defer func() {
+			if recover() != nil {
+				rw.WriteHeader(http.StatusTeapot)
+			}
+		}()
+		next(rw, r)
+	})
+	n.Use(NewTimeout(time.Second))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusTeapot)
+}
+
+func TestTimeoutTrailers(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	n.Use(NewTimeout(time.Second))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Trailer", "X-Checksum")
+		rw.Write([]byte("body"))
+		rw.Header().Set("X-Checksum", "abc123")
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Result().Trailer.Get("X-Checksum"), "abc123")
+}
+
+func TestTimeoutPanicKeepsStack(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	var value interface{}
+	var stack []byte
+
+	rec := NewRecovery()
+	rec.Logger = log.New(bytes.NewBufferString(""), "[negroni] ", 0)
+	rec.PanicHandler = func(r *http.Request, err interface{}, s []byte) {
+		value, stack = err, s
+	}
+
+	n := New()
+	n.Use(rec)
+	n.Use(NewTimeout(time.Second))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusInternalServerError)
+	expect(t, value, "boom")
+	expect(t, strings.Contains(string(stack), "TestTimeoutPanicKeepsStack.func2"), true)
+}
+
+type chanWriter chan string
+
+func (w chanWriter) Write(b []byte) (int, error) {
+	w <- string(b)
+	return len(b), nil
+}
+
+func TestTimeoutLatePanicLogged(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	logs := make(chanWriter, 1)
+
+	to := NewTimeout(10 * time.Millisecond)
+	to.Logger = log.New(logs, "[negroni] ", 0)
+
+	n := New()
+	n.Use(to)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusAccepted)
+		<-r.Context().Done()
+		time.Sleep(10 * time.Millisecond)
+		rw.Header().Set("X-Late", "yes")
+		panic("late")
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(recorder, req)
+	msg := <-logs
+	expect(t, recorder.Code, http.StatusAccepted)
+	expect(t, recorder.Header().Get("X-Late"), "")
+	expect(t, strings.HasPrefix(msg, "[negroni] PANIC after timeout: late\n"), true)
+}
+
+func TestTimeoutHeadersWithoutBody(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	n.Use(NewTimeout(time.Second))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("X-Foo", "bar")
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(recorder, req)
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, recorder.Header().Get("X-Foo"), "bar")
+}
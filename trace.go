@@ -0,0 +1,82 @@
+package negroni
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// TraceEntry records a handler a traced request went through.
+type TraceEntry struct {
+	// Handler is the type name of the handler, or of the http.Handler it wraps.
+	Handler string
+	// CalledNext reports whether the handler called the next handler in the stack. The
+	// last entry without it is the handler that short-circuited the request.
+	CalledNext bool
+}
+
+type traceKey struct{}
+
+type trace struct {
+	mu      sync.Mutex
+	entries []TraceEntry
+}
+
+// TraceFromContext returns the handlers the request ctx belongs to has gone through so far,
+// in order, or nil unless tracing was turned on with Negroni.Trace. The observer set with
+// Observe and the first handler of the stack, once next returns, see the complete trace.
+func TraceFromContext(ctx context.Context) []TraceEntry {
+	t, ok := ctx.Value(traceKey{}).(*trace)
+	if !ok {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]TraceEntry(nil), t.entries...)
+}
+
+// Trace turns request tracing on or off. While on, every handler of the stack records whether
+// it called next, which shows which middleware short-circuited a request; see
+// TraceFromContext. It adds a context value and a little bookkeeping per handler to every
+// request, so it is meant for debugging rather than production.
+func (n *Negroni) Trace(enabled bool) {
+	n.trace = enabled
+	n.rebuild()
+}
+
+// traceNodes sets up the chain starting at m, up to and including the void middleware that
+// ends it, to record traces.
+func traceNodes(m *middleware) {
+	for ; m != nil && m.handler != nil; m = m.next {
+		m.trace = true
+	}
+}
+
+// recordTrace marks the previous handler of the trace as having called next and adds m's
+// handler, unless m is the void middleware ending the chain.
+func (m middleware) recordTrace(r *http.Request) {
+	if r == nil {
+		return
+	}
+	t, ok := r.Context().Value(traceKey{}).(*trace)
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.entries) > 0 {
+		t.entries[len(t.entries)-1].CalledNext = true
+	}
+	if m.next != nil && m.next.next != nil {
+		t.entries = append(t.entries, TraceEntry{Handler: handlerName(m.handler)})
+	}
+}
+
+// handlerName returns the type name of h, or of the http.Handler it wraps.
+func handlerName(h Handler) string {
+	if w, ok := h.(wrapped); ok {
+		return fmt.Sprintf("%T", w.handler)
+	}
+	return fmt.Sprintf("%T", h)
+}
@@ -0,0 +1,57 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTraceFromContext(t *testing.T) {
+	var traced []TraceEntry
+
+	n := New()
+	n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		next(rw, r)
+	})
+	n.Trace(true)
+	n.Use(NewReadOnly())
+	n.Use(NewRequireHeaders("X-Token"))
+	n.UseHandler(http.NotFoundHandler())
+	n.Observe(func(r *http.Request, status int, duration time.Duration) {
+		traced = TraceFromContext(r.Context())
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(httptest.NewRecorder(), req)
+
+	expect(t, len(traced), 3)
+	expect(t, traced[0], TraceEntry{Handler: "negroni.HandlerFunc", CalledNext: true})
+	expect(t, traced[1], TraceEntry{Handler: "*negroni.ReadOnly", CalledNext: true})
+	expect(t, traced[2], TraceEntry{Handler: "*negroni.RequireHeaders", CalledNext: false})
+
+	req.Header.Set("X-Token", "t")
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, len(traced), 4)
+	expect(t, traced[2].CalledNext, true)
+	expect(t, traced[3], TraceEntry{Handler: "http.HandlerFunc", CalledNext: false})
+}
+
+func TestTraceDisabled(t *testing.T) {
+	var traced []TraceEntry
+
+	n := New()
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		traced = TraceFromContext(r.Context())
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, len(traced), 0)
+}
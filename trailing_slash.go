@@ -0,0 +1,91 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// SlashPolicy decides how TrailingSlash treats the trailing slash of a path.
+type SlashPolicy int
+
+const (
+	// SlashIgnore leaves the path as it is.
+	SlashIgnore SlashPolicy = iota
+	// SlashStrip redirects paths with a trailing slash to the path without it.
+	SlashStrip
+	// SlashAdd redirects paths without a trailing slash to the path with it.
+	SlashAdd
+)
+
+type slashPolicyKey struct{}
+
+// WithSlashPolicy returns a copy of ctx carrying the trailing slash policy of the matched
+// route. It takes precedence over the prefixes configured on TrailingSlash.
+func WithSlashPolicy(ctx context.Context, policy SlashPolicy) context.Context {
+	return context.WithValue(ctx, slashPolicyKey{}, policy)
+}
+
+// TrailingSlash is a middleware handler that enforces a canonical trailing slash policy per
+// route group, redirecting non-canonical paths. The policy comes from the context (see
+// WithSlashPolicy) or else from the longest matching entry in Prefixes, falling back to
+// Default. GET and HEAD requests are redirected with 301, others with 308 to keep the method.
+type TrailingSlash struct {
+	// Prefixes maps path prefixes, e.g. "/api/", to their policy.
+	Prefixes map[string]SlashPolicy
+	// Default is the policy for paths matching no prefix.
+	Default SlashPolicy
+}
+
+// NewTrailingSlash returns a new instance of TrailingSlash that ignores trailing slashes
+// unless configured otherwise
+func NewTrailingSlash() *TrailingSlash {
+	return &TrailingSlash{Prefixes: make(map[string]SlashPolicy)}
+}
+
+func (ts *TrailingSlash) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	path := r.URL.Path
+	target := path
+	switch ts.policy(r) {
+	case SlashStrip:
+		if path != "/" {
+			target = strings.TrimRight(path, "/")
+		}
+	case SlashAdd:
+		if !strings.HasSuffix(path, "/") {
+			target = path + "/"
+		}
+	}
+
+	if target == path {
+		next(rw, r)
+		return
+	}
+
+	// A Location starting with "//" or "/\" is taken by browsers as a protocol-relative URL
+	// to another host, so leading slashes are collapsed to keep the redirect on this site.
+	target = "/" + strings.TrimLeft(target, "/\\")
+
+	if r.URL.RawQuery != "" {
+		target += "?" + r.URL.RawQuery
+	}
+	status := http.StatusMovedPermanently
+	if r.Method != "GET" && r.Method != "HEAD" {
+		status = http.StatusPermanentRedirect
+	}
+	http.Redirect(rw, r, target, status)
+}
+
+func (ts *TrailingSlash) policy(r *http.Request) SlashPolicy {
+	if policy, ok := r.Context().Value(slashPolicyKey{}).(SlashPolicy); ok {
+		return policy
+	}
+
+	policy, longest := ts.Default, -1
+	for prefix, p := range ts.Prefixes {
+		if strings.HasPrefix(r.URL.Path, prefix) && len(prefix) > longest {
+			policy, longest = p, len(prefix)
+		}
+	}
+	return policy
+}
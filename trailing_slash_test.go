@@ -0,0 +1,88 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func serveTrailingSlash(t *testing.T, ts *TrailingSlash, method, target string, ctxPolicy *SlashPolicy) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	if ctxPolicy != nil {
+		n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			next(rw, r.WithContext(WithSlashPolicy(r.Context(), *ctxPolicy)))
+		})
+	}
+	n.Use(ts)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	req, err := http.NewRequest(method, "http://localhost:3000"+target, nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestTrailingSlashPerPrefix(t *testing.T) {
+	ts := NewTrailingSlash()
+	ts.Prefixes["/api/"] = SlashStrip
+	ts.Prefixes["/docs"] = SlashAdd
+	ts.Prefixes["/api/legacy/"] = SlashIgnore
+
+	recorder := serveTrailingSlash(t, ts, "GET", "/api/users/?page=2", nil)
+	expect(t, recorder.Code, http.StatusMovedPermanently)
+	expect(t, recorder.Header().Get("Location"), "/api/users?page=2")
+
+	recorder = serveTrailingSlash(t, ts, "POST", "/api/users/", nil)
+	expect(t, recorder.Code, http.StatusPermanentRedirect)
+
+	expect(t, serveTrailingSlash(t, ts, "GET", "/api/users", nil).Code, http.StatusOK)
+	expect(t, serveTrailingSlash(t, ts, "GET", "/api/legacy/users/", nil).Code, http.StatusOK)
+
+	recorder = serveTrailingSlash(t, ts, "GET", "/docs/intro", nil)
+	expect(t, recorder.Code, http.StatusMovedPermanently)
+	expect(t, recorder.Header().Get("Location"), "/docs/intro/")
+
+	expect(t, serveTrailingSlash(t, ts, "GET", "/other/", nil).Code, http.StatusOK)
+	expect(t, serveTrailingSlash(t, ts, "GET", "/other", nil).Code, http.StatusOK)
+}
+
+func TestTrailingSlashContextPolicy(t *testing.T) {
+	ts := NewTrailingSlash()
+	ts.Default = SlashStrip
+	policy := SlashAdd
+
+	recorder := serveTrailingSlash(t, ts, "GET", "/reports", &policy)
+	expect(t, recorder.Code, http.StatusMovedPermanently)
+	expect(t, recorder.Header().Get("Location"), "/reports/")
+
+	expect(t, serveTrailingSlash(t, ts, "GET", "/", nil).Code, http.StatusOK)
+}
+
+func TestTrailingSlashNoOpenRedirect(t *testing.T) {
+	strip := NewTrailingSlash()
+	strip.Default = SlashStrip
+	add := NewTrailingSlash()
+	add.Default = SlashAdd
+
+	for _, c := range []struct {
+		ts       *TrailingSlash
+		target   string
+		location string
+	}{
+		{strip, "//evil.com/", "/evil.com"},
+		{strip, "/%5Cevil.com/", "/evil.com"},
+		{strip, "///", "/"},
+		{add, "//evil.com", "/evil.com/"},
+		{add, "/%5C%5Cevil.com", "/evil.com/"},
+	} {
+		recorder := serveTrailingSlash(t, c.ts, "GET", c.target, nil)
+		expect(t, recorder.Code, http.StatusMovedPermanently)
+		expect(t, recorder.Header().Get("Location"), c.location)
+	}
+}
@@ -0,0 +1,85 @@
+package negroni
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+)
+
+// Tx is a request-scoped unit of work, such as a *sql.Tx.
+type Tx interface {
+	Commit() error
+	Rollback() error
+}
+
+type txKey struct{}
+
+// TxFromContext returns the transaction begun by the Transaction middleware for the request,
+// or nil if there is none.
+func TxFromContext(ctx context.Context) Tx {
+	tx, _ := ctx.Value(txKey{}).(Tx)
+	return tx
+}
+
+// Transaction is a middleware handler that gives every request its own transaction. It begins
+// one with BeginTx before calling the rest of the chain, and once the chain returns commits it
+// if the response status is below 400, or rolls it back otherwise. A panic rolls the
+// transaction back as it passes through without being recovered, so a Recovery placed before
+// Transaction still reports it with its original stack.
+//
+// The response may already be on its way to the client when the transaction is committed, so
+// a failed commit is logged, and only turned into a 500 if nothing has been written yet.
+type Transaction struct {
+	// BeginTx starts a transaction for a request.
+	BeginTx func(ctx context.Context) (Tx, error)
+	// Logger receives commit and rollback errors.
+	Logger *log.Logger
+}
+
+// NewTransaction returns a new instance of Transaction using begin to start transactions
+func NewTransaction(begin func(ctx context.Context) (Tx, error)) *Transaction {
+	return &Transaction{
+		BeginTx: begin,
+		Logger:  log.New(os.Stdout, "[negroni] ", 0),
+	}
+}
+
+func (t *Transaction) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	tx, err := t.BeginTx(r.Context())
+	if err != nil {
+		t.Logger.Printf("begin transaction: %v", err)
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// A panic is left to unwind untouched, so Recovery still sees the stack that raised it;
+	// the transaction is rolled back on the way.
+	returned := false
+	defer func() {
+		if !returned {
+			t.rollback(tx)
+		}
+	}()
+
+	next(rw, r.WithContext(context.WithValue(r.Context(), txKey{}, tx)))
+	returned = true
+
+	res := rw.(ResponseWriter)
+	if finalStatus(res) >= 400 {
+		t.rollback(tx)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		t.Logger.Printf("commit transaction: %v", err)
+		if !res.Written() {
+			http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+	}
+}
+
+func (t *Transaction) rollback(tx Tx) {
+	if err := tx.Rollback(); err != nil {
+		t.Logger.Printf("rollback transaction: %v", err)
+	}
+}
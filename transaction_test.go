@@ -0,0 +1,98 @@
+package negroni
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testTx struct {
+	committed  bool
+	rolledBack bool
+	commitErr  error
+}
+
+func (tx *testTx) Commit() error {
+	tx.committed = true
+	return tx.commitErr
+}
+
+func (tx *testTx) Rollback() error {
+	tx.rolledBack = true
+	return nil
+}
+
+func serveTransaction(t *testing.T, tx *testTx, handler http.HandlerFunc) *httptest.ResponseRecorder {
+	recorder, _ := serveTransactionStack(t, tx, handler)
+	return recorder
+}
+
+// serveTransactionStack also returns the stack Recovery reports if the handler panics.
+func serveTransactionStack(t *testing.T, tx *testTx, handler http.HandlerFunc) (*httptest.ResponseRecorder, []byte) {
+	var stack []byte
+	txn := NewTransaction(func(ctx context.Context) (Tx, error) { return tx, nil })
+	txn.Logger = log.New(bytes.NewBufferString(""), "[negroni] ", 0)
+
+	rec := NewRecovery()
+	rec.Logger = log.New(bytes.NewBufferString(""), "[negroni] ", 0)
+	rec.PrintStack = false
+	rec.PanicHandler = func(r *http.Request, err interface{}, s []byte) {
+		stack = s
+	}
+
+	n := New(rec, txn)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		expect(t, TxFromContext(r.Context()), Tx(tx))
+		handler(rw, r)
+	})
+
+	recorder := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", "http://localhost:3000/orders", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(recorder, req)
+	return recorder, stack
+}
+
+func TestTransactionCommit(t *testing.T) {
+	tx := &testTx{}
+	recorder := serveTransaction(t, tx, func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusCreated)
+	})
+	expect(t, recorder.Code, http.StatusCreated)
+	expect(t, tx.committed, true)
+	expect(t, tx.rolledBack, false)
+}
+
+func TestTransactionRollbackOnError(t *testing.T) {
+	tx := &testTx{}
+	serveTransaction(t, tx, func(rw http.ResponseWriter, r *http.Request) {
+		http.Error(rw, "conflict", http.StatusConflict)
+	})
+	expect(t, tx.committed, false)
+	expect(t, tx.rolledBack, true)
+}
+
+func TestTransactionRollbackOnPanic(t *testing.T) {
+	tx := &testTx{}
+	recorder, stack := serveTransactionStack(t, tx, func(rw http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	expect(t, recorder.Code, http.StatusInternalServerError)
+	expect(t, strings.Contains(string(stack), "TestTransactionRollbackOnPanic.func1"), true)
+	expect(t, tx.committed, false)
+	expect(t, tx.rolledBack, true)
+}
+
+func TestTransactionCommitFailure(t *testing.T) {
+	tx := &testTx{commitErr: errors.New("serialization failure")}
+	recorder := serveTransaction(t, tx, func(rw http.ResponseWriter, r *http.Request) {})
+	expect(t, recorder.Code, http.StatusInternalServerError)
+	expect(t, tx.committed, true)
+}
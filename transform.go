@@ -0,0 +1,54 @@
+package negroni
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+)
+
+// TransformFunc rewrites a response body.
+type TransformFunc func(body []byte) []byte
+
+// Transform is a middleware handler that applies a registered TransformFunc to responses of a
+// given content type, e.g. to minify HTML or inject a base tag. Matching responses are buffered
+// in memory until the handler returns; all other responses are streamed through untouched.
+type Transform struct {
+	// Transformers maps a media type such as "text/html" to its TransformFunc.
+	Transformers map[string]TransformFunc
+}
+
+// NewTransform returns a new instance of Transform with no transformers registered
+func NewTransform() *Transform {
+	return &Transform{Transformers: make(map[string]TransformFunc)}
+}
+
+// Register sets the TransformFunc used for responses with the given media type.
+func (t *Transform) Register(mediaType string, fn TransformFunc) {
+	t.Transformers[mediaType] = fn
+}
+
+func (t *Transform) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	buf := newResponseBuffer(rw.(ResponseWriter), func(status int, h http.Header) bool {
+		return t.transformer(h) != nil
+	})
+
+	next(buf, r)
+
+	if fn := t.transformer(buf.header); fn != nil && buf.buffered() && buf.Written() {
+		body := fn(buf.body.Bytes())
+		buf.body.Reset()
+		buf.body.Write(body)
+		if buf.header.Get("Content-Length") != "" {
+			buf.header.Set("Content-Length", strconv.Itoa(len(body)))
+		}
+	}
+	buf.flush()
+}
+
+func (t *Transform) transformer(h http.Header) TransformFunc {
+	mediaType, _, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		return nil
+	}
+	return t.Transformers[mediaType]
+}
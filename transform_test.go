@@ -0,0 +1,73 @@
+package negroni
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newUpperTransformStack(contentType string) *Negroni {
+	tr := NewTransform()
+	tr.Register("text/html", bytes.ToUpper)
+
+	n := New()
+	n.Use(tr)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", contentType)
+		rw.Write([]byte("hello "))
+		rw.Write([]byte("world"))
+	})
+	return n
+}
+
+func TestTransformMatchingContentType(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	newUpperTransformStack("text/html; charset=utf-8").ServeHTTP(recorder, (*http.Request)(nil))
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, recorder.Body.String(), "HELLO WORLD")
+}
+
+func TestTransformOtherContentType(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	newUpperTransformStack("application/json").ServeHTTP(recorder, (*http.Request)(nil))
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, recorder.Body.String(), "hello world")
+}
+
+func TestTransformNoWrite(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	n := New()
+	n.Use(NewTransform())
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("X-Foo", "bar")
+		rw.Header().Set("Location", "/next")
+	})
+
+	n.ServeHTTP(recorder, (*http.Request)(nil))
+	expect(t, recorder.Code, http.StatusOK)
+	expect(t, recorder.Body.Len(), 0)
+	expect(t, recorder.Header().Get("X-Foo"), "bar")
+	expect(t, recorder.Header().Get("Location"), "/next")
+}
+
+func TestTransformTrailers(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	tr := NewTransform()
+	tr.Register("text/html", bytes.ToUpper)
+
+	n := New()
+	n.Use(tr)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "text/html")
+		rw.Header().Set("Trailer", "X-Checksum")
+		rw.Write([]byte("body"))
+		rw.Header().Set("X-Checksum", "abc123")
+	})
+
+	n.ServeHTTP(recorder, (*http.Request)(nil))
+	expect(t, recorder.Body.String(), "BODY")
+	expect(t, recorder.Result().Trailer.Get("X-Checksum"), "abc123")
+}
@@ -0,0 +1,75 @@
+package negroni
+
+import "fmt"
+
+// HandlerRole identifies what kind of handler a middleware is, so that Validate can check the
+// order of a stack.
+type HandlerRole int
+
+const (
+	// RoleUnknown is the role of handlers that do not identify themselves.
+	RoleUnknown HandlerRole = iota
+	// RoleRecovery is the role of panic recovery middleware.
+	RoleRecovery
+	// RoleLogger is the role of request logging middleware.
+	RoleLogger
+	// RoleStatic is the role of static file serving middleware.
+	RoleStatic
+	// RoleApp is the role of application handlers, such as those added with UseHandler.
+	RoleApp
+)
+
+var roleNames = map[HandlerRole]string{
+	RoleUnknown:  "unknown",
+	RoleRecovery: "recovery",
+	RoleLogger:   "logger",
+	RoleStatic:   "static",
+	RoleApp:      "app",
+}
+
+func (r HandlerRole) String() string {
+	return roleNames[r]
+}
+
+// RoleHandler is implemented by handlers that identify their role to Validate.
+type RoleHandler interface {
+	Role() HandlerRole
+}
+
+func handlerRole(h Handler) HandlerRole {
+	if rh, ok := h.(RoleHandler); ok {
+		return rh.Role()
+	}
+	return RoleUnknown
+}
+
+// Validate checks the middleware stack for common ordering mistakes. Handlers that do not
+// implement RoleHandler are ignored. The rules are:
+//
+// Recovery must come before every other handler with a role, otherwise panics in those
+// handlers are not recovered.
+//
+// Logger and Static must come before any application handler, otherwise they only see
+// requests the application did not answer.
+func (n *Negroni) Validate() error {
+	sawRole, sawApp := false, false
+	for i, h := range n.handlers {
+		role := handlerRole(h)
+		switch role {
+		case RoleUnknown:
+			continue
+		case RoleRecovery:
+			if sawRole {
+				return fmt.Errorf("negroni: recovery handler at position %d must be first", i)
+			}
+		case RoleLogger, RoleStatic:
+			if sawApp {
+				return fmt.Errorf("negroni: %s handler at position %d must come before application handlers", role, i)
+			}
+		case RoleApp:
+			sawApp = true
+		}
+		sawRole = true
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package negroni
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestValidateClassic(t *testing.T) {
+	n := Classic()
+	n.UseHandler(http.NotFoundHandler())
+	expect(t, n.Validate(), nil)
+}
+
+func TestValidateEmpty(t *testing.T) {
+	expect(t, New().Validate(), nil)
+}
+
+func TestValidateRecoveryNotFirst(t *testing.T) {
+	n := New(NewLogger(), NewRecovery())
+	err := n.Validate()
+	refute(t, err, nil)
+	expect(t, err.Error(), "negroni: recovery handler at position 1 must be first")
+}
+
+func TestValidateLoggerAfterApp(t *testing.T) {
+	n := New(NewRecovery())
+	n.UseHandler(http.NotFoundHandler())
+	n.Use(NewLogger())
+	err := n.Validate()
+	refute(t, err, nil)
+	expect(t, err.Error(), "negroni: logger handler at position 2 must come before application handlers")
+}
+
+func TestValidateIgnoresUnknownHandlers(t *testing.T) {
+	n := New()
+	n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		next(rw, r)
+	})
+	n.Use(NewRecovery())
+	n.UseHandler(http.NotFoundHandler())
+	expect(t, n.Validate(), nil)
+}
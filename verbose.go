@@ -0,0 +1,169 @@
+package negroni
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type verboseKey struct{}
+
+// IsVerbose reports whether verbose logging was enabled for the request ctx belongs to, so
+// handlers can log extra detail of their own.
+func IsVerbose(ctx context.Context) bool {
+	v, _ := ctx.Value(verboseKey{}).(bool)
+	return v
+}
+
+// Verbose is a middleware handler that turns on detailed logging for single requests while
+// keeping the rest terse. A request is verbose when it carries Header with a non-empty value
+// and comes from a trusted source, or when Sample selects it. Verbose requests get their
+// request and response headers, the first BodySnippet bytes of both bodies and a timing
+// breakdown logged once they complete. The values of credential headers listed in
+// RedactHeaders are logged as REDACTED.
+type Verbose struct {
+	// Header is the request header asking for verbose logging.
+	Header string
+	// Trusted lists the IP addresses or CIDR ranges allowed to ask for verbose logging.
+	// Requests from other sources are logged normally.
+	Trusted []string
+	// Sample optionally selects requests to log verbosely regardless of the header.
+	Sample func(r *http.Request) bool
+	// BodySnippet is the number of body bytes logged for requests and responses.
+	BodySnippet int
+	// RedactHeaders lists the request and response headers whose values are replaced with
+	// REDACTED. It defaults to Authorization, Proxy-Authorization, Cookie and Set-Cookie.
+	RedactHeaders []string
+	// Logger receives the verbose entries.
+	Logger *log.Logger
+}
+
+// NewVerbose returns a new instance of Verbose honoring the X-Debug header from the trusted
+// addresses or CIDR ranges and logging up to 512 bytes of each body
+func NewVerbose(trusted ...string) *Verbose {
+	return &Verbose{
+		Header:      "X-Debug",
+		Trusted:     trusted,
+		BodySnippet: 512,
+		RedactHeaders: []string{
+			"Authorization",
+			"Proxy-Authorization",
+			"Cookie",
+			"Set-Cookie",
+		},
+		Logger: log.New(os.Stdout, "[negroni] ", 0),
+	}
+}
+
+func (v *Verbose) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if !v.enabled(r) {
+		next(rw, r)
+		return
+	}
+
+	start := time.Now()
+	reqBody := &snippetBuffer{limit: v.BodySnippet}
+	if r.Body != nil {
+		r.Body = teeReadCloser{io.TeeReader(r.Body, reqBody), r.Body}
+	}
+	var firstByte time.Duration
+	res := &verboseWriter{ResponseWriter: rw.(ResponseWriter), body: &snippetBuffer{limit: v.BodySnippet}}
+	res.Before(func(ResponseWriter) {
+		firstByte = time.Since(start)
+	})
+
+	next(res, r.WithContext(context.WithValue(r.Context(), verboseKey{}, true)))
+
+	var buf bytes.Buffer
+	buf.WriteString("VERBOSE " + r.Method + " " + r.URL.RequestURI() + " from " + r.RemoteAddr + "\n")
+	v.writeHeaders(&buf, "> ", r.Header)
+	if reqBody.Len() > 0 {
+		buf.WriteString("> " + reqBody.String() + "\n")
+	}
+	v.writeHeaders(&buf, "< ", res.Header())
+	if res.body.Len() > 0 {
+		buf.WriteString("< " + res.body.String() + "\n")
+	}
+	buf.WriteString("status=" + strconv.Itoa(finalStatus(res)) + " first_byte=" + firstByte.String() +
+		" total=" + time.Since(start).String())
+	v.Logger.Println(buf.String())
+}
+
+func (v *Verbose) enabled(r *http.Request) bool {
+	if v.Sample != nil && v.Sample(r) {
+		return true
+	}
+	if r.Header.Get(v.Header) == "" {
+		return false
+	}
+	return trustedClient(r, v.Trusted)
+}
+
+func (v *Verbose) writeHeaders(buf *bytes.Buffer, prefix string, h http.Header) {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		value := strings.Join(h[k], ", ")
+		for _, redact := range v.RedactHeaders {
+			if http.CanonicalHeaderKey(redact) == k {
+				value = "REDACTED"
+				break
+			}
+		}
+		buf.WriteString(prefix + k + ": " + value + "\n")
+	}
+}
+
+// snippetBuffer keeps the first limit bytes written to it and discards the rest.
+type snippetBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *snippetBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); room > 0 {
+		if len(p) > room {
+			b.Buffer.Write(p[:room])
+		} else {
+			b.Buffer.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+type teeReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// verboseWriter copies the start of the response body into a snippetBuffer.
+type verboseWriter struct {
+	ResponseWriter
+	body *snippetBuffer
+}
+
+func (w *verboseWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.body.Write(p[:n])
+	return n, err
+}
+
+func (w *verboseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return hijack(w.ResponseWriter)
+}
+
+func (w *verboseWriter) CloseNotify() <-chan bool {
+	return closeNotify(w.ResponseWriter)
+}
@@ -0,0 +1,118 @@
+package negroni
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVerbosePerRequest(t *testing.T) {
+	buff := bytes.NewBufferString("")
+	v := NewVerbose("10.0.0.0/8", "192.168.1.5")
+	v.Logger = log.New(buff, "", 0)
+	v.BodySnippet = 8
+
+	var verbose bool
+	n := New()
+	n.Use(v)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		verbose = IsVerbose(r.Context())
+		ioutil.ReadAll(r.Body)
+		rw.Header().Set("Content-Type", "text/plain")
+		rw.Write([]byte("a long response body"))
+	})
+
+	serve := func(remoteAddr string, debug bool) {
+		buff.Reset()
+		req, err := http.NewRequest("POST", "http://localhost:3000/orders?id=1", strings.NewReader("request payload"))
+		if err != nil {
+			t.Error(err)
+		}
+		req.RemoteAddr = remoteAddr
+		if debug {
+			req.Header.Set("X-Debug", "1")
+		}
+		n.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	serve("10.1.2.3:5000", false)
+	expect(t, verbose, false)
+	expect(t, buff.String(), "")
+
+	// Untrusted sources cannot turn verbose logging on.
+	serve("203.0.113.9:5000", true)
+	expect(t, verbose, false)
+	expect(t, buff.String(), "")
+
+	serve("10.1.2.3:5000", true)
+	expect(t, verbose, true)
+	out := buff.String()
+	expect(t, strings.HasPrefix(out, "VERBOSE POST /orders?id=1 from 10.1.2.3:5000\n"), true)
+	expect(t, strings.Contains(out, "> X-Debug: 1\n"), true)
+	expect(t, strings.Contains(out, "> request \n"), true)
+	expect(t, strings.Contains(out, "< Content-Type: text/plain\n"), true)
+	expect(t, strings.Contains(out, "< a long r\n"), true)
+	expect(t, strings.Contains(out, "status=200 first_byte="), true)
+
+	serve("192.168.1.5:5000", true)
+	expect(t, verbose, true)
+}
+
+func TestVerboseSample(t *testing.T) {
+	buff := bytes.NewBufferString("")
+	v := NewVerbose()
+	v.Logger = log.New(buff, "", 0)
+	v.Sample = func(r *http.Request) bool { return r.URL.Path == "/sampled" }
+
+	n := New()
+	n.Use(v)
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/sampled", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	n.ServeHTTP(httptest.NewRecorder(), req)
+	expect(t, strings.HasPrefix(buff.String(), "VERBOSE GET /sampled"), true)
+}
+
+func TestVerboseRedactsCredentials(t *testing.T) {
+	buff := bytes.NewBufferString("")
+	v := NewVerbose()
+	v.Logger = log.New(buff, "", 0)
+	v.Sample = func(r *http.Request) bool { return true }
+
+	n := New()
+	n.Use(v)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Set-Cookie", "session=secret")
+		rw.Header().Set("X-Token", "t0ken")
+	})
+
+	serve := func() string {
+		buff.Reset()
+		req, err := http.NewRequest("GET", "http://localhost:3000/", nil)
+		if err != nil {
+			t.Error(err)
+		}
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Set("Cookie", "session=secret")
+		n.ServeHTTP(httptest.NewRecorder(), req)
+		return buff.String()
+	}
+
+	out := serve()
+	expect(t, strings.Contains(out, "secret"), false)
+	expect(t, strings.Contains(out, "> Authorization: REDACTED\n"), true)
+	expect(t, strings.Contains(out, "> Cookie: REDACTED\n"), true)
+	expect(t, strings.Contains(out, "< Set-Cookie: REDACTED\n"), true)
+	expect(t, strings.Contains(out, "< X-Token: t0ken\n"), true)
+
+	v.RedactHeaders = []string{"x-token"}
+	out = serve()
+	expect(t, strings.Contains(out, "> Authorization: Bearer secret\n"), true)
+	expect(t, strings.Contains(out, "< X-Token: REDACTED\n"), true)
+}
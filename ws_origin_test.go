@@ -0,0 +1,72 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newWSRequest(t *testing.T, origin string) *http.Request {
+	req, err := http.NewRequest("GET", "http://localhost:3000/ws", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("Connection", "keep-alive, Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	return req
+}
+
+func TestWSOriginAllowed(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	called := false
+
+	n := New()
+	n.Use(NewWSOrigin("https://example.com"))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	n.ServeHTTP(recorder, newWSRequest(t, "https://example.com"))
+	expect(t, called, true)
+	expect(t, recorder.Code, http.StatusOK)
+}
+
+func TestWSOriginDisallowed(t *testing.T) {
+	for _, origin := range []string{"https://evil.com", ""} {
+		recorder := httptest.NewRecorder()
+		called := false
+
+		n := New()
+		n.Use(NewWSOrigin("https://example.com"))
+		n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			called = true
+		})
+
+		n.ServeHTTP(recorder, newWSRequest(t, origin))
+		expect(t, called, false)
+		expect(t, recorder.Code, http.StatusForbidden)
+	}
+}
+
+func TestWSOriginNotUpgrade(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	called := false
+
+	n := New()
+	n.Use(NewWSOrigin("https://example.com"))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	req, err := http.NewRequest("GET", "http://localhost:3000/ws", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("Origin", "https://evil.com")
+
+	n.ServeHTTP(recorder, req)
+	expect(t, called, true)
+}